@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/breach"
+	"golang.org/x/exp/slog"
+	"os"
+	"time"
+)
+
+// build-breach-filter turns a HIBP corpus into the filter BREACH_PATH can point at:
+//
+//	go run ./cmd/build-breach-filter -corpus pwned.txt -out breach.filter
+func main() {
+	if err := buildBreachFilter(os.Args[1:]); err != nil {
+		slog.Error("Error building breach filter: ", "error", err)
+		os.Exit(1)
+	}
+}
+
+func buildBreachFilter(args []string) error {
+	flags := flag.NewFlagSet("build-breach-filter", flag.ContinueOnError)
+
+	corpus := flags.String("corpus", "", "HIBP SHA-1 password file ordered by hash")
+	out := flags.String("out", "", "path of the filter to write")
+	minCount := flags.Int64("min-count", 1, "leave out hashes seen in fewer breaches")
+	falsePositiveRate := flags.Float64("false-positive-rate", 0.001, "share of never breached passwords the filter refuses")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *corpus == "" || *out == "" {
+		flags.Usage()
+		return errors.New("-corpus and -out are required")
+	}
+
+	start := time.Now()
+
+	n, err := breach.BuildFilter(*corpus, *out, *minCount, *falsePositiveRate)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Breach filter built", "hashes", n, "path", *out, "duration", time.Since(start))
+
+	return nil
+}
@@ -0,0 +1,15 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// Store persists opaque objects under slash-separated keys.
+type Store interface {
+	// Put writes the object and returns a location the operator can retrieve it from.
+	Put(ctx context.Context, key string, data io.Reader) (location string, err error)
+}
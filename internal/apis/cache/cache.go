@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"math/rand/v2"
+	"time"
+)
+
+var ErrMiss = errors.New("cache miss")
+
+// Cache keeps users addressable both by id and by slug. Implementations must
+// write and drop both keys together so a user never lingers under one of them,
+// and InvalidateUser must drop every slug the user was cached under, even once
+// the id entry is gone.
+type Cache interface {
+	GetUserByID(ctx context.Context, userID int64) (*models.User, error)
+	GetUserBySlug(ctx context.Context, slug string) (*models.User, error)
+	SetUser(ctx context.Context, user *models.User) error
+	InvalidateUser(ctx context.Context, userID int64) error
+}
+
+func idKey(userID int64) string {
+	return fmt.Sprintf("users:id:%d", userID)
+}
+
+func slugKey(slug string) string {
+	return fmt.Sprintf("users:slug:%s", slug)
+}
+
+// slugsKey holds every slug a user was cached under, so a renamed user's old slug is dropped too.
+func slugsKey(userID int64) string {
+	return fmt.Sprintf("users:slugs:%d", userID)
+}
+
+// jitter spreads expirations of entries written together so they do not all expire at once.
+func jitter(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return ttl
+	}
+	return ttl + rand.N(ttl/10+1)
+}
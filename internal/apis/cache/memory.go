@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"sync"
+	"time"
+)
+
+var _ Cache = (*MemoryCache)(nil)
+
+// MemoryCache is a process-local Cache used in tests and local runs without Redis.
+type MemoryCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[string]memoryEntry
+	slugs   map[int64]map[string]struct{}
+}
+
+type memoryEntry struct {
+	user      models.User
+	expiresAt time.Time
+}
+
+func NewMemoryCache(ttl time.Duration) *MemoryCache {
+	return &MemoryCache{
+		ttl:     ttl,
+		entries: make(map[string]memoryEntry),
+		slugs:   make(map[int64]map[string]struct{}),
+	}
+}
+
+func (c *MemoryCache) GetUserByID(_ context.Context, userID int64) (*models.User, error) {
+	return c.get(idKey(userID))
+}
+
+func (c *MemoryCache) GetUserBySlug(_ context.Context, slug string) (*models.User, error) {
+	return c.get(slugKey(slug))
+}
+
+func (c *MemoryCache) SetUser(_ context.Context, user *models.User) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := memoryEntry{user: *user, expiresAt: time.Now().Add(jitter(c.ttl))}
+	c.entries[idKey(user.ID)] = entry
+	c.entries[slugKey(user.Slug)] = entry
+
+	if c.slugs[user.ID] == nil {
+		c.slugs[user.ID] = make(map[string]struct{})
+	}
+	c.slugs[user.ID][user.Slug] = struct{}{}
+
+	return nil
+}
+
+func (c *MemoryCache) InvalidateUser(_ context.Context, userID int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, idKey(userID))
+
+	for slug := range c.slugs[userID] {
+		delete(c.entries, slugKey(slug))
+	}
+	delete(c.slugs, userID)
+
+	return nil
+}
+
+func (c *MemoryCache) get(key string) (*models.User, error) {
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrMiss
+	}
+
+	if time.Now().After(entry.expiresAt) {
+		c.mu.Lock()
+		if current, exists := c.entries[key]; exists && time.Now().After(current.expiresAt) {
+			delete(c.entries, key)
+		}
+		c.mu.Unlock()
+		return nil, ErrMiss
+	}
+
+	user := entry.user
+	return &user, nil
+}
@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+var _ Cache = (*RedisCache)(nil)
+
+type RedisCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewRedisCache(client *redis.Client, ttl time.Duration) *RedisCache {
+	return &RedisCache{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+func (c *RedisCache) GetUserByID(ctx context.Context, userID int64) (*models.User, error) {
+	return c.get(ctx, idKey(userID))
+}
+
+func (c *RedisCache) GetUserBySlug(ctx context.Context, slug string) (*models.User, error) {
+	return c.get(ctx, slugKey(slug))
+}
+
+func (c *RedisCache) SetUser(ctx context.Context, user *models.User) error {
+	data, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+
+	ttl := jitter(c.ttl)
+
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, idKey(user.ID), data, ttl)
+		pipe.Set(ctx, slugKey(user.Slug), data, ttl)
+		pipe.SAdd(ctx, slugsKey(user.ID), user.Slug)
+		pipe.Expire(ctx, slugsKey(user.ID), ttl)
+		return nil
+	})
+
+	return err
+}
+
+func (c *RedisCache) InvalidateUser(ctx context.Context, userID int64) error {
+	slugs, err := c.client.SMembers(ctx, slugsKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := []string{idKey(userID), slugsKey(userID)}
+	for _, slug := range slugs {
+		keys = append(keys, slugKey(slug))
+	}
+
+	return c.client.Del(ctx, keys...).Err()
+}
+
+func (c *RedisCache) get(ctx context.Context, key string) (*models.User, error) {
+	data, err := c.client.Get(ctx, key).Bytes()
+	switch {
+	case errors.Is(err, redis.Nil):
+		return nil, ErrMiss
+	case err != nil:
+		return nil, err
+	}
+
+	var user models.User
+	if err = json.Unmarshal(data, &user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
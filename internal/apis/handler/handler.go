@@ -5,32 +5,36 @@ import (
 	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
 	"github.com/Brain-Wave-Ecosystem/go-common/pkg/helpers"
 	users "github.com/Brain-Wave-Ecosystem/users-service/gen/users"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/lockout"
 	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/service"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/clientip"
 	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
-	"github.com/bufbuild/protovalidate-go"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/rbac"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"net/http"
+	"path"
 	"strconv"
+	"time"
 )
 
 var _ users.UsersServiceServer = (*Handler)(nil)
 
+const exportChunkSize = 32 << 10
+
 type Handler struct {
-	service   *service.Service
-	logger    *zap.Logger
-	validator protovalidate.Validator
+	service *service.Service
+	logger  *zap.Logger
 
 	users.UnimplementedUsersServiceServer
 }
 
 func NewHandler(service *service.Service, logger *zap.Logger) *Handler {
-	v, _ := protovalidate.New(protovalidate.WithFailFast())
-
 	return &Handler{
-		service:   service,
-		logger:    logger,
-		validator: v,
+		service: service,
+		logger:  logger,
 	}
 }
 
@@ -57,13 +61,194 @@ func (h *Handler) GetUserProfile(ctx context.Context, _ *emptypb.Empty) (*users.
 	return &users.GetUserProfileResponse{User: user.ToGRPC()}, nil
 }
 
+func (h *Handler) ListUsers(ctx context.Context, request *users.ListUsersRequest) (*users.ListUsersResponse, error) {
+	list, nextPageToken, err := h.service.ListUsers(ctx, models.ToListUsersFilter(request), request.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &users.ListUsersResponse{
+		Users:         make([]*users.User, 0, len(list)),
+		NextPageToken: nextPageToken,
+	}
+
+	for _, user := range list {
+		resp.Users = append(resp.Users, user.ToGRPC())
+	}
+
+	return resp, nil
+}
+
+func (h *Handler) SearchUsers(ctx context.Context, request *users.SearchUsersRequest) (*users.SearchUsersResponse, error) {
+	caller, _ := rbac.CallerFromContext(ctx)
+
+	hits, nextPageToken, err := h.service.SearchUsers(ctx, caller, request.GetQuery(), int(request.GetPageSize()), request.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &users.SearchUsersResponse{
+		Hits:          make([]*users.UserSearchHit, 0, len(hits)),
+		NextPageToken: nextPageToken,
+	}
+
+	for _, hit := range hits {
+		resp.Hits = append(resp.Hits, hit.ToGRPC())
+	}
+
+	return resp, nil
+}
+
 func (h *Handler) LoginUserByEmail(ctx context.Context, request *users.LoginUserByEmailRequest) (*users.LoginUserResponse, error) {
-	user, err := h.service.GetUserByEmail(ctx, request.GetEmail(), request.GetPassword())
+	clientIP := clientip.FromContext(ctx)
+
+	result, err := h.service.Login(ctx, request.GetEmail(), request.GetPassword(), clientIP)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.User != nil {
+		result.Session, err = h.service.StartSession(ctx, result.User.ID, extractUserAgent(ctx), clientIP)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result.ToGRPC(), nil
+}
+
+func (h *Handler) VerifyMfaLogin(ctx context.Context, request *users.VerifyMfaLoginRequest) (*users.LoginUserResponse, error) {
+	clientIP := clientip.FromContext(ctx)
+
+	user, err := h.service.VerifyMFALogin(ctx, request.GetChallengeId(), request.GetCode(), request.GetRecoveryCode(), clientIP)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := h.service.StartSession(ctx, user.ID, extractUserAgent(ctx), clientIP)
+	if err != nil {
+		return nil, err
+	}
+
+	return (&models.LoginResult{User: user, Session: session}).ToGRPC(), nil
+}
+
+func (h *Handler) RefreshSession(ctx context.Context, request *users.RefreshSessionRequest) (*users.RefreshSessionResponse, error) {
+	issued, user, err := h.service.RefreshSession(ctx, request.GetRefreshToken(), extractUserAgent(ctx), clientip.FromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return &users.RefreshSessionResponse{
+		User:         user.ToGRPC(),
+		Session:      issued.Session.ToGRPC(),
+		RefreshToken: issued.RefreshToken,
+	}, nil
+}
+
+func (h *Handler) ListSessions(ctx context.Context, _ *emptypb.Empty) (*users.ListSessionsResponse, error) {
+	caller, _ := rbac.CallerFromContext(ctx)
+
+	sessions, err := h.service.ListSessions(ctx, caller.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &users.ListSessionsResponse{Sessions: make([]*users.Session, 0, len(sessions))}
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, session.ToGRPC())
+	}
+
+	return response, nil
+}
+
+func (h *Handler) RevokeSession(ctx context.Context, request *users.RevokeSessionRequest) (*emptypb.Empty, error) {
+	caller, _ := rbac.CallerFromContext(ctx)
+
+	err := h.service.RevokeSession(ctx, caller.UserID, request.GetSessionId())
+	return nil, err
+}
+
+func (h *Handler) RevokeAllSessions(ctx context.Context, _ *emptypb.Empty) (*users.RevokeAllSessionsResponse, error) {
+	caller, _ := rbac.CallerFromContext(ctx)
+
+	revoked, err := h.service.RevokeAllSessions(ctx, caller.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &users.RevokeAllSessionsResponse{Revoked: revoked}, nil
+}
+
+func (h *Handler) EnrollMfa(ctx context.Context, _ *emptypb.Empty) (*users.EnrollMfaResponse, error) {
+	userID, err := h.extractUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, uri, err := h.service.EnrollMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &users.EnrollMfaResponse{Secret: secret, OtpauthUri: uri}, nil
+}
+
+func (h *Handler) ConfirmMfaEnrollment(ctx context.Context, request *users.ConfirmMfaEnrollmentRequest) (*users.MfaRecoveryCodesResponse, error) {
+	userID, err := h.extractUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := h.service.ConfirmMFAEnrollment(ctx, userID, request.GetCode())
+	if err != nil {
+		return nil, err
+	}
+
+	return &users.MfaRecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (h *Handler) RegenerateMfaRecoveryCodes(ctx context.Context, request *users.RegenerateMfaRecoveryCodesRequest) (*users.MfaRecoveryCodesResponse, error) {
+	userID, err := h.extractUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := h.service.RegenerateMFARecoveryCodes(ctx, userID, request.GetCode())
 	if err != nil {
 		return nil, err
 	}
 
-	return &users.LoginUserResponse{User: user.ToGRPC()}, nil
+	return &users.MfaRecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (h *Handler) DisableMfa(ctx context.Context, request *users.DisableMfaRequest) (*emptypb.Empty, error) {
+	userID, err := h.extractUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.service.DisableMFA(ctx, userID, request.GetPassword(), request.GetCode())
+	return nil, err
+}
+
+func (h *Handler) GetLoginLockout(ctx context.Context, request *users.GetLoginLockoutRequest) (*users.GetLoginLockoutResponse, error) {
+	account, client, err := h.service.GetLoginLockout(ctx, request.GetEmail(), request.GetIp())
+	if err != nil {
+		return nil, err
+	}
+
+	return &users.GetLoginLockoutResponse{
+		Account: lockoutToGRPC(account),
+		Ip:      lockoutToGRPC(client),
+	}, nil
+}
+
+func (h *Handler) ClearLoginLockout(ctx context.Context, request *users.ClearLoginLockoutRequest) (*emptypb.Empty, error) {
+	caller, _ := rbac.CallerFromContext(ctx)
+
+	err := h.service.ClearLoginLockout(ctx, caller, request.GetEmail(), request.GetIp())
+	return nil, err
 }
 
 func (h *Handler) CreateUser(ctx context.Context, request *users.CreateUserRequest) (*users.CreateUserResponse, error) {
@@ -76,7 +261,19 @@ func (h *Handler) CreateUser(ctx context.Context, request *users.CreateUserReque
 }
 
 func (h *Handler) ConfirmUser(ctx context.Context, request *users.UserConfirmRequest) (*emptypb.Empty, error) {
-	err := h.service.ConfirmUser(ctx, request.UserId)
+	caller, _ := rbac.CallerFromContext(ctx)
+
+	err := h.service.ConfirmUser(ctx, caller, request.UserId)
+	return nil, err
+}
+
+func (h *Handler) RequestEmailVerification(ctx context.Context, request *users.RequestEmailVerificationRequest) (*emptypb.Empty, error) {
+	err := h.service.RequestEmailVerification(ctx, request.GetEmail())
+	return nil, err
+}
+
+func (h *Handler) VerifyEmail(ctx context.Context, request *users.VerifyEmailRequest) (*emptypb.Empty, error) {
+	err := h.service.VerifyEmail(ctx, request.GetToken())
 	return nil, err
 }
 
@@ -90,7 +287,7 @@ func (h *Handler) UpdateUser(ctx context.Context, request *users.UpdateUserReque
 		return nil, apperrors.Forbidden("You do not have permission to modify this user's data")
 	}
 
-	err = h.service.UpdateUser(ctx, userID, &models.UpdateUser{
+	err = h.service.UpdateUser(ctx, rbac.Caller{UserID: userID}, userID, &models.UpdateUser{
 		AvatarURL: request.AvatarUrl,
 		FullName:  request.FullName,
 		Bio:       request.Bio,
@@ -103,7 +300,9 @@ func (h *Handler) UpdateUser(ctx context.Context, request *users.UpdateUserReque
 }
 
 func (h *Handler) UpdateUserAdmin(ctx context.Context, request *users.UpdateUserAdminRequest) (*emptypb.Empty, error) {
-	err := h.service.UpdateUser(ctx, request.GetId(), &models.UpdateUser{
+	caller, _ := rbac.CallerFromContext(ctx)
+
+	err := h.service.UpdateUser(ctx, caller, request.GetId(), &models.UpdateUser{
 		AvatarURL: request.AvatarUrl,
 		FullName:  request.FullName,
 		Bio:       request.Bio,
@@ -127,7 +326,7 @@ func (h *Handler) UpdateUserPassword(ctx context.Context, request *users.UpdateU
 		return nil, apperrors.Forbidden("You do not have permission to modify this user's data")
 	}
 
-	err = h.service.UpdateUserPassword(ctx, userID, request.GetPassword())
+	err = h.service.UpdateUserPassword(ctx, rbac.Caller{UserID: userID}, userID, request.GetPassword())
 	if err != nil {
 		return nil, err
 	}
@@ -136,7 +335,9 @@ func (h *Handler) UpdateUserPassword(ctx context.Context, request *users.UpdateU
 }
 
 func (h *Handler) UpdateUserPasswordAdmin(ctx context.Context, request *users.UpdateUserPasswordAdminRequest) (*emptypb.Empty, error) {
-	err := h.service.UpdateUserPassword(ctx, request.GetId(), request.GetPassword())
+	caller, _ := rbac.CallerFromContext(ctx)
+
+	err := h.service.UpdateUserPassword(ctx, caller, request.GetId(), request.GetPassword())
 	if err != nil {
 		return nil, err
 	}
@@ -144,6 +345,16 @@ func (h *Handler) UpdateUserPasswordAdmin(ctx context.Context, request *users.Up
 	return nil, nil
 }
 
+func (h *Handler) RequestPasswordReset(ctx context.Context, request *users.RequestPasswordResetRequest) (*emptypb.Empty, error) {
+	err := h.service.RequestPasswordReset(ctx, request.GetEmail())
+	return nil, err
+}
+
+func (h *Handler) ResetPassword(ctx context.Context, request *users.ResetPasswordRequest) (*emptypb.Empty, error) {
+	err := h.service.ResetPassword(ctx, request.GetToken(), request.GetPassword())
+	return nil, err
+}
+
 func (h *Handler) DeleteUser(ctx context.Context, request *users.DeleteUserRequest) (*emptypb.Empty, error) {
 	userID, err := h.extractUserID(ctx)
 	if err != nil {
@@ -154,13 +365,120 @@ func (h *Handler) DeleteUser(ctx context.Context, request *users.DeleteUserReque
 		return nil, apperrors.Forbidden("You do not have permission to modify this user's data")
 	}
 
-	err = h.service.DeleteUser(ctx, userID)
+	err = h.service.DeleteUser(ctx, rbac.Caller{UserID: userID}, userID)
 
 	return nil, err
 }
 
 func (h *Handler) DeleteUserAdmin(ctx context.Context, request *users.DeleteUserRequest) (*emptypb.Empty, error) {
-	err := h.service.DeleteUser(ctx, request.Id)
+	caller, _ := rbac.CallerFromContext(ctx)
+
+	err := h.service.DeleteUser(ctx, caller, request.Id)
+	return nil, err
+}
+
+// RestoreUser lets users undo their own deletion; restoring anyone else needs PermUsersRestore.
+func (h *Handler) RestoreUser(ctx context.Context, request *users.RestoreUserRequest) (*emptypb.Empty, error) {
+	caller, _ := rbac.CallerFromContext(ctx)
+
+	if caller.UserID != request.GetId() && !caller.Role.Can(rbac.PermUsersRestore) {
+		return nil, apperrors.Forbidden("You do not have permission to restore this user")
+	}
+
+	err := h.service.RestoreUser(ctx, caller, request.GetId())
+	return nil, err
+}
+
+// EraseUser lets users erase their own account after confirming their password; erasing anyone else needs
+// PermUsersErase.
+func (h *Handler) EraseUser(ctx context.Context, request *users.EraseUserRequest) (*users.EraseUserResponse, error) {
+	caller, _ := rbac.CallerFromContext(ctx)
+
+	if caller.UserID != request.GetId() && !caller.Role.Can(rbac.PermUsersErase) {
+		return nil, apperrors.Forbidden("You do not have permission to erase this user")
+	}
+
+	receipt, err := h.service.EraseUser(ctx, caller, request.GetId(), request.GetPassword(), request.GetCode())
+	if err != nil {
+		return nil, err
+	}
+
+	return &users.EraseUserResponse{
+		ReceiptId: receipt.ID,
+		ErasedAt:  timestamppb.New(receipt.ErasedAt),
+	}, nil
+}
+
+// ExportUserData streams the caller's own data archive in chunks; the first one names the file.
+func (h *Handler) ExportUserData(_ *users.ExportUserDataRequest, stream users.UsersService_ExportUserDataServer) error {
+	ctx := stream.Context()
+
+	caller, _ := rbac.CallerFromContext(ctx)
+
+	data, err := h.service.ExportUserData(ctx, caller.UserID)
+	if err != nil {
+		return err
+	}
+
+	chunk := &users.ExportUserDataChunk{
+		ContentType: "application/json",
+		Filename:    path.Base(service.ExportFilename(caller.UserID, time.Now())),
+	}
+
+	for offset := 0; offset < len(data); offset += exportChunkSize {
+		chunk.Data = data[offset:min(offset+exportChunkSize, len(data))]
+
+		if err = stream.Send(chunk); err != nil {
+			return err
+		}
+
+		chunk = &users.ExportUserDataChunk{}
+	}
+
+	return nil
+}
+
+func (h *Handler) ExportUserDataAdmin(ctx context.Context, request *users.ExportUserDataAdminRequest) (*users.ExportUserDataAdminResponse, error) {
+	location, err := h.service.ExportUserDataToBlob(ctx, request.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &users.ExportUserDataAdminResponse{
+		Location:  location.Location,
+		SizeBytes: location.SizeBytes,
+		Sha256:    location.SHA256,
+	}, nil
+}
+
+func (h *Handler) ChangeUserRole(ctx context.Context, request *users.ChangeUserRoleRequest) (*emptypb.Empty, error) {
+	caller, _ := rbac.CallerFromContext(ctx)
+
+	err := h.service.ChangeUserRole(ctx, caller, request.GetUserId(), request.GetRole(), request.GetReason())
+	return nil, err
+}
+
+func (h *Handler) SuspendUser(ctx context.Context, request *users.SuspendUserRequest) (*emptypb.Empty, error) {
+	caller, _ := rbac.CallerFromContext(ctx)
+
+	suspension := &models.Suspension{
+		UserID: request.GetUserId(),
+		Reason: request.GetReason(),
+	}
+
+	if request.GetSuspendedUntil() != nil {
+		until := request.GetSuspendedUntil().AsTime()
+		suspension.Until = &until
+	}
+
+	err := h.service.SuspendUser(ctx, caller, suspension)
+	return nil, err
+}
+
+func (h *Handler) UnsuspendUser(ctx context.Context, request *users.UnsuspendUserRequest) (*emptypb.Empty, error) {
+	caller, _ := rbac.CallerFromContext(ctx)
+
+	err := h.service.UnsuspendUser(ctx, caller, request.GetUserId())
 	return nil, err
 }
 
@@ -182,3 +500,29 @@ func (h *Handler) extractUserID(ctx context.Context) (int64, error) {
 
 	return id, nil
 }
+
+// extractUserAgent prefers the browser's user agent forwarded by the gateway over the gRPC client's own.
+func extractUserAgent(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	for _, key := range []string{"grpcgateway-user-agent", "user-agent"} {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+	}
+
+	return ""
+}
+
+func lockoutToGRPC(s *lockout.Status) *users.LoginLockout {
+	result := &users.LoginLockout{FailedAttempts: int32(s.Failures)}
+
+	if s.LockedUntil != nil && time.Now().Before(*s.LockedUntil) {
+		result.LockedUntil = timestamppb.New(*s.LockedUntil)
+	}
+
+	return result
+}
@@ -0,0 +1,31 @@
+package lockout
+
+import (
+	"context"
+	"time"
+)
+
+// Counter keeps failed login attempts and lockouts per key (an account email or a client IP).
+type Counter interface {
+	Get(ctx context.Context, key string) (*Status, error)
+	RecordFailure(ctx context.Context, key string, window time.Duration) (int, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+type Status struct {
+	Failures    int
+	LockedUntil *time.Time
+}
+
+func (s *Status) Locked(now time.Time) bool {
+	return s.LockedUntil != nil && now.Before(*s.LockedUntil)
+}
+
+func failuresKey(key string) string {
+	return "lockout:failures:" + key
+}
+
+func lockKey(key string) string {
+	return "lockout:lock:" + key
+}
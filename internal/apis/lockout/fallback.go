@@ -0,0 +1,63 @@
+package lockout
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"time"
+)
+
+var _ Counter = (*FallbackCounter)(nil)
+
+// FallbackCounter uses the primary counter and switches to the secondary one for any call the primary fails,
+// so a Redis outage weakens protection to per-instance counting instead of disabling it.
+type FallbackCounter struct {
+	primary   Counter
+	secondary Counter
+	logger    *zap.Logger
+}
+
+func NewFallbackCounter(primary, secondary Counter, logger *zap.Logger) *FallbackCounter {
+	return &FallbackCounter{
+		primary:   primary,
+		secondary: secondary,
+		logger:    logger,
+	}
+}
+
+func (c *FallbackCounter) Get(ctx context.Context, key string) (*Status, error) {
+	status, err := c.primary.Get(ctx, key)
+	if err != nil {
+		c.warn("get", err)
+		return c.secondary.Get(ctx, key)
+	}
+	return status, nil
+}
+
+func (c *FallbackCounter) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	failures, err := c.primary.RecordFailure(ctx, key, window)
+	if err != nil {
+		c.warn("record failure", err)
+		return c.secondary.RecordFailure(ctx, key, window)
+	}
+	return failures, nil
+}
+
+func (c *FallbackCounter) Lock(ctx context.Context, key string, until time.Time) error {
+	if err := c.primary.Lock(ctx, key, until); err != nil {
+		c.warn("lock", err)
+		return c.secondary.Lock(ctx, key, until)
+	}
+	return nil
+}
+
+func (c *FallbackCounter) Reset(ctx context.Context, key string) error {
+	if err := c.primary.Reset(ctx, key); err != nil {
+		c.warn("reset", err)
+		return c.secondary.Reset(ctx, key)
+	}
+	return c.secondary.Reset(ctx, key)
+}
+
+func (c *FallbackCounter) warn(op string, err error) {
+	c.logger.Warn("users-service | lockout counter unavailable, using in-memory fallback", zap.String("op", op), zap.Error(err))
+}
@@ -0,0 +1,203 @@
+package lockout
+
+import (
+	"context"
+	"fmt"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/config"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/events"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"strings"
+	"time"
+)
+
+// Guard applies the login throttling policy: progressive delays after each failure and a temporary
+// lockout once an account or a client IP exceeds its failure budget within the window.
+type Guard struct {
+	counter   Counter
+	cfg       config.LockoutConfig
+	publisher events.Publisher
+	logger    *zap.Logger
+}
+
+func NewGuard(counter Counter, cfg config.LockoutConfig, publisher events.Publisher, logger *zap.Logger) *Guard {
+	return &Guard{
+		counter:   counter,
+		cfg:       cfg,
+		publisher: publisher,
+		logger:    logger,
+	}
+}
+
+// Check rejects the attempt with codes.ResourceExhausted and a retry delay while the account or IP is locked.
+func (g *Guard) Check(ctx context.Context, email, ip string) error {
+	now := time.Now()
+
+	for _, key := range g.keys(email, ip) {
+		st, err := g.counter.Get(ctx, key)
+		if err != nil {
+			g.logger.Warn("users-service | lockout check failed", zap.String("key", key), zap.Error(err))
+			continue
+		}
+
+		if st.Locked(now) {
+			return lockedError(st.LockedUntil.Sub(now))
+		}
+	}
+
+	return nil
+}
+
+// Fail records a failed attempt for the account and IP, locks whichever exceeded its limit and then
+// holds the caller for the progressive delay. userID is 0 when the email has no account.
+func (g *Guard) Fail(ctx context.Context, email, ip string, userID int64) {
+	failures := 0
+
+	if email != "" {
+		failures = g.recordFailure(ctx, accountKey(email), g.cfg.MaxAccountAttempts, func(until time.Time) {
+			// Emails without an account are locked all the same, so a lock does not reveal that the
+			// account exists, but there is nobody to notify.
+			if userID != 0 {
+				g.publishLocked(ctx, userID, email, until)
+			}
+		})
+	}
+
+	if ip != "" {
+		g.recordFailure(ctx, ipKey(ip), g.cfg.MaxIPAttempts, nil)
+	}
+
+	g.wait(ctx, g.delay(failures))
+}
+
+func (g *Guard) Succeed(ctx context.Context, email string) {
+	if err := g.counter.Reset(ctx, accountKey(email)); err != nil {
+		g.logger.Warn("users-service | failed to reset login attempts", zap.Error(err))
+	}
+}
+
+func (g *Guard) Status(ctx context.Context, email, ip string) (account, client *Status, err error) {
+	account, client = &Status{}, &Status{}
+
+	if email != "" {
+		if account, err = g.counter.Get(ctx, accountKey(email)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if ip != "" {
+		if client, err = g.counter.Get(ctx, ipKey(ip)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return account, client, nil
+}
+
+func (g *Guard) Clear(ctx context.Context, email, ip string) error {
+	for _, key := range g.keys(email, ip) {
+		if err := g.counter.Reset(ctx, key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (g *Guard) recordFailure(ctx context.Context, key string, limit int, onLock func(until time.Time)) int {
+	failures, err := g.counter.RecordFailure(ctx, key, g.cfg.Window)
+	if err != nil {
+		g.logger.Warn("users-service | failed to record login failure", zap.String("key", key), zap.Error(err))
+		return 0
+	}
+
+	if failures < limit {
+		return failures
+	}
+
+	until := time.Now().Add(g.cfg.Duration)
+	if err = g.counter.Lock(ctx, key, until); err != nil {
+		g.logger.Warn("users-service | failed to lock login", zap.String("key", key), zap.Error(err))
+		return failures
+	}
+
+	if failures == limit && onLock != nil {
+		onLock(until)
+	}
+
+	return failures
+}
+
+func (g *Guard) publishLocked(ctx context.Context, userID int64, email string, until time.Time) {
+	event, err := events.AccountLocked(userID, email, until)
+	if err == nil {
+		err = g.publisher.Publish(ctx, event)
+	}
+
+	if err != nil {
+		g.logger.Warn("users-service | failed to publish account locked event", zap.String("email", email), zap.Error(err))
+	}
+}
+
+// delay doubles BaseDelay with every consecutive failure, capped at MaxDelay.
+func (g *Guard) delay(failures int) time.Duration {
+	if failures <= 0 || g.cfg.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := g.cfg.BaseDelay
+	for i := 1; i < failures && delay < g.cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, g.cfg.MaxDelay)
+}
+
+func (g *Guard) wait(ctx context.Context, delay time.Duration) {
+	if delay <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+func (g *Guard) keys(email, ip string) []string {
+	keys := make([]string, 0, 2)
+	if email != "" {
+		keys = append(keys, accountKey(email))
+	}
+	if ip != "" {
+		keys = append(keys, ipKey(ip))
+	}
+	return keys
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(email)
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+func lockedError(retryAfter time.Duration) error {
+	retryAfter = retryAfter.Round(time.Second)
+
+	st := status.New(codes.ResourceExhausted, fmt.Sprintf("too many failed login attempts, retry after %d seconds", int(retryAfter.Seconds())))
+
+	detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+	if err != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
@@ -0,0 +1,61 @@
+package lockout
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Limiter throttles an action per key on the lockout counters: attempts must be Cooldown apart and at
+// most Max may start within Window. It knows nothing about accounts, so a key is limited the same
+// whether or not anything exists behind it.
+type Limiter struct {
+	counter  Counter
+	prefix   string
+	cooldown time.Duration
+	max      int
+	window   time.Duration
+}
+
+func NewLimiter(counter Counter, prefix string, cooldown time.Duration, max int, window time.Duration) *Limiter {
+	return &Limiter{
+		counter:  counter,
+		prefix:   prefix,
+		cooldown: cooldown,
+		max:      max,
+		window:   window,
+	}
+}
+
+// Allow records an attempt for key. It returns how long the caller has to wait when the attempt is
+// refused, and whether the refusal is for exceeding Max rather than the cooldown.
+func (l *Limiter) Allow(ctx context.Context, key string) (retryAfter time.Duration, exhausted bool, err error) {
+	key = l.prefix + ":" + strings.ToLower(key)
+	now := time.Now()
+
+	st, err := l.counter.Get(ctx, key)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if st.Locked(now) {
+		return st.LockedUntil.Sub(now), false, nil
+	}
+
+	attempts, err := l.counter.RecordFailure(ctx, key, l.window)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if attempts > l.max {
+		return l.window, true, nil
+	}
+
+	if l.cooldown > 0 {
+		if err = l.counter.Lock(ctx, key, now.Add(l.cooldown)); err != nil {
+			return 0, false, err
+		}
+	}
+
+	return 0, false, nil
+}
@@ -0,0 +1,61 @@
+package lockout
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	type attempt struct {
+		key           string
+		wantRefused   bool
+		wantExhausted bool
+	}
+
+	tests := []struct {
+		name     string
+		cooldown time.Duration
+		max      int
+		attempts []attempt
+	}{
+		{
+			name:     "cooldown",
+			cooldown: time.Minute,
+			max:      5,
+			attempts: []attempt{
+				{key: "ana@example.com"},
+				{key: "ANA@example.com", wantRefused: true},
+				{key: "bob@example.com"},
+			},
+		},
+		{
+			name: "max per window",
+			max:  2,
+			attempts: []attempt{
+				{key: "ana@example.com"},
+				{key: "ana@example.com"},
+				{key: "ana@example.com", wantRefused: true, wantExhausted: true},
+				{key: "bob@example.com"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewLimiter(NewMemoryCounter(), "test", tt.cooldown, tt.max, time.Hour)
+
+			for i, a := range tt.attempts {
+				retryAfter, exhausted, err := limiter.Allow(context.Background(), a.key)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if refused := retryAfter > 0; refused != a.wantRefused || exhausted != a.wantExhausted {
+					t.Errorf("attempt %d for %s: retryAfter %s, exhausted %v, want refused %v, exhausted %v",
+						i+1, a.key, retryAfter, exhausted, a.wantRefused, a.wantExhausted)
+				}
+			}
+		})
+	}
+}
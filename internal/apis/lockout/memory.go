@@ -0,0 +1,105 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var _ Counter = (*MemoryCounter)(nil)
+
+// MemoryCounter is a process-local Counter for tests, local runs and Redis outages.
+type MemoryCounter struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+type memoryEntry struct {
+	failures     int
+	windowEndsAt time.Time
+	lockedUntil  *time.Time
+}
+
+func NewMemoryCounter() *MemoryCounter {
+	return &MemoryCounter{entries: make(map[string]*memoryEntry)}
+}
+
+func (c *MemoryCounter) Get(_ context.Context, key string) (*Status, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := c.entry(key, time.Now())
+	if entry == nil {
+		return &Status{}, nil
+	}
+
+	return &Status{Failures: entry.failures, LockedUntil: entry.lockedUntil}, nil
+}
+
+func (c *MemoryCounter) RecordFailure(_ context.Context, key string, window time.Duration) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	entry := c.entry(key, now)
+	if entry == nil {
+		entry = &memoryEntry{}
+		c.entries[key] = entry
+	}
+
+	if entry.failures == 0 {
+		entry.windowEndsAt = now.Add(window)
+	}
+
+	entry.failures++
+
+	return entry.failures, nil
+}
+
+func (c *MemoryCounter) Lock(_ context.Context, key string, until time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := c.entry(key, time.Now())
+	if entry == nil {
+		entry = &memoryEntry{}
+		c.entries[key] = entry
+	}
+
+	entry.lockedUntil = &until
+
+	return nil
+}
+
+func (c *MemoryCounter) Reset(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+
+	return nil
+}
+
+// entry returns the live state for key, expiring the failure window and lock independently. Callers hold mu.
+func (c *MemoryCounter) entry(key string, now time.Time) *memoryEntry {
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+
+	if entry.failures > 0 && !now.Before(entry.windowEndsAt) {
+		entry.failures = 0
+	}
+
+	if entry.lockedUntil != nil && !now.Before(*entry.lockedUntil) {
+		entry.lockedUntil = nil
+	}
+
+	if entry.failures == 0 && entry.lockedUntil == nil {
+		delete(c.entries, key)
+		return nil
+	}
+
+	return entry
+}
@@ -0,0 +1,74 @@
+package lockout
+
+import (
+	"context"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+var _ Counter = (*RedisCounter)(nil)
+
+type RedisCounter struct {
+	client *redis.Client
+}
+
+func NewRedisCounter(client *redis.Client) *RedisCounter {
+	return &RedisCounter{client: client}
+}
+
+func (c *RedisCounter) Get(ctx context.Context, key string) (*Status, error) {
+	values, err := c.client.MGet(ctx, failuresKey(key), lockKey(key)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{}
+
+	if raw, ok := values[0].(string); ok {
+		if status.Failures, err = strconv.Atoi(raw); err != nil {
+			return nil, err
+		}
+	}
+
+	if raw, ok := values[1].(string); ok {
+		millis, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		until := time.UnixMilli(millis)
+		status.LockedUntil = &until
+	}
+
+	return status, nil
+}
+
+func (c *RedisCounter) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	var incr *redis.IntCmd
+
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, failuresKey(key))
+		pipe.ExpireNX(ctx, failuresKey(key), window)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(incr.Val()), nil
+}
+
+func (c *RedisCounter) Lock(ctx context.Context, key string, until time.Time) error {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return errors.New("lockout must end in the future")
+	}
+
+	return c.client.Set(ctx, lockKey(key), until.UnixMilli(), ttl).Err()
+}
+
+func (c *RedisCounter) Reset(ctx context.Context, key string) error {
+	return c.client.Del(ctx, failuresKey(key), lockKey(key)).Err()
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/store"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/rbac"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// EraseUser anonymizes userID on behalf of actor. Unlike DeleteUser it cannot be undone, so users erasing
+// their own account must confirm it with their password and, with MFA enabled, a current code, like DisableMFA.
+func (s *Service) EraseUser(ctx context.Context, actor rbac.Caller, userID int64, password, code string) (*models.ErasureReceipt, error) {
+	if actor.UserID == userID {
+		if err := s.reauthenticate(ctx, userID, password, code); err != nil {
+			return nil, err
+		}
+	}
+
+	var receipt *models.ErasureReceipt
+
+	err := s.manageUser(ctx, actor, userID, func(tx *store.Store) (err error) {
+		receipt, err = tx.EraseUser(ctx, userID, actor.UserID)
+		return err
+	})
+
+	switch {
+	case errors.Is(err, store.ErrLastAdmin):
+		return nil, status.Error(codes.FailedPrecondition, store.ErrLastAdmin.Error())
+	case err != nil:
+		return nil, err
+	}
+
+	s.invalidateUser(ctx, userID)
+
+	s.logger.Info("users-service | user erased", zap.Int64("user_id", userID), zap.Int64("receipt_id", receipt.ID))
+
+	return receipt, nil
+}
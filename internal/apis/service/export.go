@@ -0,0 +1,56 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"go.uber.org/zap"
+	"time"
+)
+
+// ExportUserData renders the user's data archive as indented JSON.
+func (s *Service) ExportUserData(ctx context.Context, userID int64) ([]byte, error) {
+	export, err := s.store.GetUserExport(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return data, nil
+}
+
+// ExportUserDataToBlob writes the archive to the blob store for an operator to hand over.
+func (s *Service) ExportUserDataToBlob(ctx context.Context, userID int64) (*models.ExportLocation, error) {
+	data, err := s.ExportUserData(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := s.blobs.Put(ctx, ExportFilename(userID, time.Now()), bytes.NewReader(data))
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	sum := sha256.Sum256(data)
+
+	s.logger.Info("users-service | user data exported", zap.Int64("user_id", userID), zap.String("location", location))
+
+	return &models.ExportLocation{
+		Location:  location,
+		SizeBytes: int64(len(data)),
+		SHA256:    hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+func ExportFilename(userID int64, at time.Time) string {
+	return fmt.Sprintf("exports/%d/users-export-v%d-%s.json", userID, models.UserExportVersion, at.UTC().Format("20060102T150405Z"))
+}
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"errors"
+	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/store"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/metrics"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/mfa"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/passwords"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/tokens"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// totpSkew accepts codes from one time step before or after the current one to tolerate clock drift.
+const totpSkew = 1
+
+var errInvalidMFACode = errors.New("invalid mfa code")
+
+// Login checks the password and, for users with MFA enabled, returns a short-lived challenge
+// to be completed with VerifyMFALogin instead of the user. The failed attempts are only reset once
+// every factor has passed.
+func (s *Service) Login(ctx context.Context, email, password, clientIP string) (*models.LoginResult, error) {
+	user, err := s.GetUserByEmail(ctx, email, password, clientIP)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollment, err := s.store.GetMFA(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !enrollment.Enabled() {
+		s.lockout.Succeed(ctx, user.Email)
+		return &models.LoginResult{User: user}, nil
+	}
+
+	challengeID, hash, err := tokens.Generate()
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	expiresAt := time.Now().Add(s.cfg.MFA.ChallengeTTL)
+	if err = s.store.CreateMFAChallenge(ctx, user.ID, hash, expiresAt); err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResult{Challenge: &models.MFAChallenge{ID: challengeID, ExpiresAt: expiresAt}}, nil
+}
+
+// VerifyMFALogin completes a login challenge with a TOTP or recovery code. A wrong code counts as a
+// failed login for the account and client IP, the same as a wrong password.
+func (s *Service) VerifyMFALogin(ctx context.Context, challengeID, code, recoveryCode, clientIP string) (*models.User, error) {
+	challengeHash := tokens.Hash(challengeID)
+
+	userID, err := s.store.AttemptMFAChallenge(ctx, challengeHash, s.cfg.MFA.MaxChallengeAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.store.GetUserByID(ctx, int(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.lockout.Check(ctx, user.Email, clientIP); err != nil {
+		s.metrics.Login(metrics.ResultLocked)
+		return nil, err
+	}
+
+	enrollment, err := s.store.GetMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !enrollment.Enabled() {
+		return nil, apperrors.NotFound("mfa", "user_id", userID)
+	}
+
+	if err = s.verifySecondFactor(ctx, enrollment, code, recoveryCode); err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			s.lockout.Fail(ctx, user.Email, clientIP, userID)
+			s.metrics.Login(metrics.ResultFailed)
+		}
+		return nil, err
+	}
+
+	if err = s.store.ConsumeMFAChallenge(ctx, challengeHash); err != nil {
+		return nil, err
+	}
+
+	s.lockout.Succeed(ctx, user.Email)
+
+	return user, nil
+}
+
+func (s *Service) verifySecondFactor(ctx context.Context, enrollment *models.UserMFA, code, recoveryCode string) error {
+	if recoveryCode == "" {
+		return s.verifyTOTP(ctx, enrollment, code)
+	}
+
+	used, err := s.store.UseRecoveryCode(ctx, enrollment.UserID, mfa.HashRecoveryCode(recoveryCode))
+	if err != nil {
+		return err
+	}
+
+	if !used {
+		return apperrors.BadRequestHidden(errInvalidMFACode, "invalid recovery code")
+	}
+
+	return nil
+}
+
+// EnrollMFA starts enrollment with a fresh secret and returns it with its otpauth:// URI. MFA stays off
+// until ConfirmMFAEnrollment proves the authenticator produces valid codes.
+func (s *Service) EnrollMFA(ctx context.Context, userID int64) (secret, uri string, err error) {
+	enrollment, err := s.store.GetMFA(ctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+
+	if enrollment.Enabled() {
+		return "", "", apperrors.AlreadyExists("mfa", "user_id", userID)
+	}
+
+	user, err := s.store.GetUserByID(ctx, int(userID))
+	if err != nil {
+		return "", "", err
+	}
+
+	raw, err := mfa.GenerateSecret()
+	if err != nil {
+		return "", "", apperrors.Internal(err)
+	}
+
+	encrypted, err := s.mfaCipher.Encrypt(raw)
+	if err != nil {
+		return "", "", apperrors.Internal(err)
+	}
+
+	if err = s.store.UpsertPendingMFA(ctx, userID, encrypted); err != nil {
+		return "", "", err
+	}
+
+	return mfa.EncodeSecret(raw), mfa.URI(s.cfg.MFA.Issuer, user.Email, raw), nil
+}
+
+// ConfirmMFAEnrollment enables MFA after the first valid code and returns the recovery codes,
+// which are shown to the user only this once.
+func (s *Service) ConfirmMFAEnrollment(ctx context.Context, userID int64, code string) ([]string, error) {
+	enrollment, err := s.store.GetMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case enrollment == nil:
+		return nil, apperrors.NotFound("pending mfa", "user_id", userID)
+	case enrollment.Enabled():
+		return nil, apperrors.AlreadyExists("mfa", "user_id", userID)
+	}
+
+	if err = s.verifyTOTP(ctx, enrollment, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := mfa.GenerateRecoveryCodes(s.cfg.MFA.RecoveryCodes)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	err = s.store.WithTx(ctx, func(tx *store.Store) error {
+		if err := tx.ConfirmMFA(ctx, userID); err != nil {
+			return err
+		}
+
+		return tx.ReplaceRecoveryCodes(ctx, userID, hashes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (s *Service) RegenerateMFARecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error) {
+	enrollment, err := s.enabledMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.verifyTOTP(ctx, enrollment, code); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(ctx, userID)
+}
+
+// DisableMFA requires both the account password and a current code.
+func (s *Service) DisableMFA(ctx context.Context, userID int64, password, code string) error {
+	if err := s.checkPassword(ctx, userID, password); err != nil {
+		return err
+	}
+
+	enrollment, err := s.enabledMFA(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err = s.verifyTOTP(ctx, enrollment, code); err != nil {
+		return err
+	}
+
+	return s.store.DeleteMFA(ctx, userID)
+}
+
+// reauthenticate confirms a sensitive action of the signed-in user with the password and, when MFA is
+// enabled, a current TOTP code.
+func (s *Service) reauthenticate(ctx context.Context, userID int64, password, code string) error {
+	if err := s.checkPassword(ctx, userID, password); err != nil {
+		return err
+	}
+
+	enrollment, err := s.store.GetMFA(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !enrollment.Enabled() {
+		return nil
+	}
+
+	return s.verifyTOTP(ctx, enrollment, code)
+}
+
+func (s *Service) checkPassword(ctx context.Context, userID int64, password string) error {
+	hash, err := s.store.GetPasswordHash(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	match, err := s.comparePassword(ctx, hash, password)
+	if err != nil {
+		return err
+	}
+
+	if !match {
+		return apperrors.BadRequestHidden(passwords.ErrMismatch, "invalid password")
+	}
+
+	return nil
+}
+
+func (s *Service) enabledMFA(ctx context.Context, userID int64) (*models.UserMFA, error) {
+	enrollment, err := s.store.GetMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !enrollment.Enabled() {
+		return nil, apperrors.NotFound("mfa", "user_id", userID)
+	}
+
+	return enrollment, nil
+}
+
+func (s *Service) verifyTOTP(ctx context.Context, enrollment *models.UserMFA, code string) error {
+	secret, err := s.mfaCipher.Decrypt(enrollment.SecretEncrypted)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	step, ok := mfa.Validate(secret, code, time.Now(), totpSkew)
+	if !ok {
+		return apperrors.BadRequestHidden(errInvalidMFACode, "invalid mfa code")
+	}
+
+	fresh, err := s.store.UseMFAStep(ctx, enrollment.UserID, step)
+	if err != nil {
+		return err
+	}
+
+	if !fresh {
+		return apperrors.BadRequestHidden(errInvalidMFACode, "mfa code was already used")
+	}
+
+	return nil
+}
+
+func (s *Service) replaceRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	codes, hashes, err := mfa.GenerateRecoveryCodes(s.cfg.MFA.RecoveryCodes)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	if err = s.store.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+// totpCode computes the RFC 6238 code an authenticator app shows for secret, as returned by EnrollMFA, at t.
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(at.Unix()/30))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%06d", value%1_000_000)
+}
+
+func TestLoginWithMFA(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	user := s.createTestUser(t, "Mara Factor")
+
+	secret, _, err := s.EnrollMFA(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recoveryCodes, err := s.ConfirmMFAEnrollment(ctx, user.ID, totpCode(t, secret, time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	challenge := func() string {
+		t.Helper()
+
+		result, err := s.Login(ctx, user.Email, testPassword, "203.0.113.7")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result.User != nil || result.Challenge == nil {
+			t.Fatalf("Login = %+v, want a challenge instead of the user", result)
+		}
+
+		return result.Challenge.ID
+	}
+
+	if _, err = s.VerifyMFALogin(ctx, challenge(), totpCode(t, secret, time.Now().Add(-10*time.Minute)), "", "203.0.113.7"); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("VerifyMFALogin(stale code) = %v, want InvalidArgument", err)
+	}
+
+	// The next step is within the skew and was not used to confirm the enrollment.
+	code := totpCode(t, secret, time.Now().Add(30*time.Second))
+
+	got, err := s.VerifyMFALogin(ctx, challenge(), code, "", "203.0.113.7")
+	if err != nil || got.ID != user.ID {
+		t.Fatalf("VerifyMFALogin = %v, %v, want the user", got, err)
+	}
+
+	if _, err = s.VerifyMFALogin(ctx, challenge(), code, "", "203.0.113.7"); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("VerifyMFALogin(replayed code) = %v, want InvalidArgument", err)
+	}
+
+	if _, err = s.VerifyMFALogin(ctx, challenge(), "", recoveryCodes[0], "203.0.113.7"); err != nil {
+		t.Fatalf("VerifyMFALogin(recovery code) = %v, want nil", err)
+	}
+
+	if _, err = s.VerifyMFALogin(ctx, challenge(), "", recoveryCodes[0], "203.0.113.7"); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("VerifyMFALogin(used recovery code) = %v, want InvalidArgument", err)
+	}
+}
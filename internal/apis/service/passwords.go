@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/metrics"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/passwords"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// maxHistoryDepth caps PasswordPolicy.HistoryDepth, since every entry costs a full hash comparison.
+const maxHistoryDepth = 24
+
+// errInvalidCredentials is returned for both an unknown email and a wrong password.
+var errInvalidCredentials = apperrors.BadRequestHidden(passwords.ErrMismatch, "invalid email or password")
+
+func (s *Service) hashPassword(ctx context.Context, password string) (string, error) {
+	var hash string
+	var hashErr error
+
+	err := s.runHashing(ctx, metrics.OperationHash, func() {
+		hash, hashErr = s.passwords.Hash(password)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if hashErr != nil {
+		return "", apperrors.Internal(hashErr)
+	}
+
+	return hash, nil
+}
+
+// comparePassword reports whether password matches hash. A hash that cannot be verified at all, such
+// as the empty one of an erased account, never matches; err is only set when the comparison could not run.
+func (s *Service) comparePassword(ctx context.Context, hash, password string) (bool, error) {
+	var verifyErr error
+
+	err := s.runHashing(ctx, metrics.OperationCompare, func() {
+		verifyErr = s.passwords.Verify(hash, password)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return verifyErr == nil, nil
+}
+
+// compareDummyPassword runs the same comparison as comparePassword against a hash of a random password,
+// made with the current algorithm, for logins whose email has no account.
+func (s *Service) compareDummyPassword(ctx context.Context, password string) (bool, error) {
+	hash, err := s.dummyHash()
+	if err != nil {
+		return false, apperrors.Internal(err)
+	}
+
+	return s.comparePassword(ctx, hash, password)
+}
+
+// runHashing runs fn on the hashing pool and maps a refusal to a status the client can act on.
+func (s *Service) runHashing(ctx context.Context, operation string, fn func()) error {
+	err := s.hashPool.Do(ctx, func() {
+		start := time.Now()
+		fn()
+		s.metrics.ObservePasswordHash(operation, time.Since(start))
+	})
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, passwords.ErrSaturated):
+		s.metrics.PasswordHashRejected()
+		return status.Error(codes.ResourceExhausted, "too many password operations in progress, try again later")
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		return apperrors.Internal(err)
+	}
+}
+
+// upgradePasswordHash re-hashes a just verified password whose stored hash uses an outdated algorithm
+// or cost. A failure only postpones the upgrade to the next login.
+func (s *Service) upgradePasswordHash(ctx context.Context, userID int64, oldHash, password string) {
+	if !s.passwords.NeedsRehash(oldHash) {
+		return
+	}
+
+	hash, err := s.hashPassword(ctx, password)
+	if err != nil {
+		s.logger.Warn("users-service | failed to rehash password", zap.Int64("user_id", userID), zap.Error(err))
+		return
+	}
+
+	if err = s.store.ReplacePasswordHash(ctx, userID, oldHash, hash); err != nil {
+		s.logger.Warn("users-service | failed to store rehashed password", zap.Int64("user_id", userID), zap.Error(err))
+	}
+}
+
+// passwordViolations applies the password policy and the breached password check. account holds the
+// user's email, name and slug.
+func (s *Service) passwordViolations(password string, account ...string) ([]passwords.Violation, error) {
+	violations := s.policy.Check(password, account...)
+
+	breached, err := s.breaches.Breached(password)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	if breached {
+		violations = append(violations, passwords.Violation{Rule: passwords.RuleBreached, Message: "password has appeared in a data breach"})
+	}
+
+	return violations, nil
+}
+
+// passwordReused reports whether password matches one of the user's last PasswordPolicy.HistoryDepth passwords;
+// a depth of 0 or less disables the check. Comparisons run one at a time, so a single request holds at most
+// one hashing worker.
+func (s *Service) passwordReused(ctx context.Context, userID int64, password string) (bool, error) {
+	depth := min(s.cfg.PasswordPolicy.HistoryDepth, maxHistoryDepth)
+	if depth <= 0 {
+		return false, nil
+	}
+
+	histories, err := s.store.GetPasswordHistory(ctx, userID, depth)
+	if err != nil {
+		return false, apperrors.Internal(err)
+	}
+
+	for _, history := range histories {
+		match, err := s.comparePassword(ctx, history.PasswordHash, password)
+		if err != nil {
+			return false, err
+		}
+
+		if match {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// passwordPolicyError lists every violated rule as a BadRequest field violation on "password",
+// with the rule as its reason.
+func passwordPolicyError(violations []passwords.Violation) error {
+	st := status.New(codes.InvalidArgument, "password does not meet the password policy")
+
+	details := &errdetails.BadRequest{}
+	for _, violation := range violations {
+		details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       "password",
+			Description: violation.Message,
+			Reason:      violation.Rule,
+		})
+	}
+
+	if detailed, err := st.WithDetails(details); err == nil {
+		st = detailed
+	}
+
+	return st.Err()
+}
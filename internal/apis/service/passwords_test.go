@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"github.com/Brain-Wave-Ecosystem/go-common/pkg/helpers"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/breach"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/passwords"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestCreateUserRejectsPasswordContainingSlug(t *testing.T) {
+	breaches, err := breach.Open("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The policy is checked before anything is stored, so no database is needed.
+	s := &Service{
+		policy:   passwords.NewPolicy(passwords.PolicyParams{MinLength: 10, MaxLength: 128, MinCharacterClasses: 2, MinEntropyBits: 45}),
+		breaches: breaches,
+	}
+
+	name := "zoë ångström"
+
+	// The longest slug part, which may be transliterated and so differ from every part of the name.
+	var token string
+	for _, part := range strings.FieldsFunc(helpers.GenerateSlug(name), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		if len(part) > len(token) {
+			token = part
+		}
+	}
+
+	_, err = s.CreateUser(context.Background(), &models.UserWithPassword{
+		User:         &models.User{FullName: name, Email: "zq7.contact@example.com"},
+		UserPassword: &models.UserPassword{PasswordHash: token + "-Harbor-42-comet"},
+	})
+
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("err = %v, want InvalidArgument", err)
+	}
+
+	for _, detail := range st.Details() {
+		request, ok := detail.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+
+		for _, violation := range request.GetFieldViolations() {
+			if violation.GetReason() == passwords.RuleSimilarToAccount {
+				return
+			}
+		}
+	}
+
+	t.Errorf("violations = %v, want %s", st.Details(), passwords.RuleSimilarToAccount)
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/store"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/config"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/metrics"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/rbac"
+	"go.uber.org/zap"
+	"time"
+)
+
+// RestoreUser brings back an account deleted less than the purge grace period ago.
+func (s *Service) RestoreUser(ctx context.Context, actor rbac.Caller, userID int64) error {
+	deletedAfter := time.Now().Add(-s.cfg.Purge.GracePeriod)
+
+	err := s.manageUser(ctx, actor, userID, func(tx *store.Store) error {
+		return tx.RestoreUser(ctx, userID, deletedAfter)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateUser(ctx, userID)
+
+	return nil
+}
+
+// PurgeDeletedUsers is run periodically to permanently remove accounts whose grace period is over.
+// One failing account is logged and skipped so it cannot block the rest of the batch.
+func (s *Service) PurgeDeletedUsers(ctx context.Context) error {
+	cfg := s.cfg.Purge
+	cutoff := time.Now().Add(-cfg.GracePeriod)
+	anonymize := cfg.Mode != config.PurgeModeDelete
+
+	mode := config.PurgeModeDelete
+	if anonymize {
+		mode = config.PurgeModeAnonymize
+	}
+
+	candidates, err := s.store.PurgeCandidates(ctx, cutoff, cfg.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range candidates {
+		if cfg.DryRun {
+			s.logger.Info("users-service | purge dry run", zap.Int64("user_id", userID), zap.String("mode", mode))
+			s.metrics.Purged(mode, metrics.ResultDryRun)
+			continue
+		}
+
+		purged, err := s.store.PurgeUser(ctx, userID, cutoff, anonymize)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			s.logger.Error("users-service | failed to purge user", zap.Int64("user_id", userID), zap.Error(err))
+			s.metrics.Purged(mode, metrics.ResultFailed)
+			continue
+		}
+
+		if purged {
+			s.metrics.Purged(mode, metrics.ResultSucceeded)
+			s.invalidateUser(ctx, userID)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/store"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/tokens"
+	"go.uber.org/zap"
+	"time"
+)
+
+// RequestPasswordReset emails a reset token to an active account. It returns the same result whether
+// or not the email exists, and silently drops requests inside the resend cooldown.
+func (s *Service) RequestPasswordReset(ctx context.Context, email string) error {
+	userID, _, found, err := s.store.FindUserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return nil
+	}
+
+	now := time.Now()
+
+	latest, err := s.store.LatestPasswordResetTokenAt(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if latest != nil && now.Sub(*latest) < s.cfg.PasswordReset.ResendCooldown {
+		return nil
+	}
+
+	token, hash, err := tokens.Generate()
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	if err = s.store.CreatePasswordResetToken(ctx, userID, hash, now.Add(s.cfg.PasswordReset.TokenTTL)); err != nil {
+		return err
+	}
+
+	if err = s.notifier.SendPasswordReset(ctx, email, token); err != nil {
+		s.logger.Error("users-service | failed to send password reset email", zap.Int64("user_id", userID), zap.Error(err))
+		return apperrors.Internal(err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password for the owner of a valid reset token. The policy and reuse checks
+// run against the token's owner first, so a rejected password leaves the token usable; the token itself
+// is then consumed atomically in the same transaction as the password change, together with every
+// other outstanding token, so concurrent resets with one token succeed at most once.
+func (s *Service) ResetPassword(ctx context.Context, token, password string) error {
+	tokenHash := tokens.Hash(token)
+
+	userID, err := s.store.GetPasswordResetTokenOwner(ctx, tokenHash)
+	if err != nil {
+		return err
+	}
+
+	passwordHash, err := s.newPasswordHash(ctx, userID, password)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.WithTx(ctx, func(tx *store.Store) error {
+		owner, err := tx.ConsumePasswordResetToken(ctx, tokenHash)
+		if err != nil {
+			return err
+		}
+
+		if err = setPassword(ctx, tx, owner, passwordHash); err != nil {
+			return err
+		}
+
+		return tx.InvalidatePasswordResetTokens(ctx, owner)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateUser(ctx, userID)
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestPasswordReset(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	user := s.createTestUser(t, "Rhea Reset")
+
+	if err := s.RequestPasswordReset(ctx, user.Email); err != nil {
+		t.Fatal(err)
+	}
+
+	token := s.notifier.token("reset:" + user.Email)
+	if token == "" {
+		t.Fatal("no reset token was sent")
+	}
+
+	// Inside the cooldown a second request succeeds without sending another token.
+	if err := s.RequestPasswordReset(ctx, user.Email); err != nil || s.notifier.token("reset:"+user.Email) != token {
+		t.Errorf("second RequestPasswordReset = %v, want nil and no new token", err)
+	}
+
+	if err := s.RequestPasswordReset(ctx, "nobody@example.com"); err != nil || s.notifier.token("reset:nobody@example.com") != "" {
+		t.Errorf("RequestPasswordReset(unknown email) = %v, want nil and no token", err)
+	}
+
+	// A rejected password leaves the token usable.
+	if err := s.ResetPassword(ctx, token, testPassword); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("ResetPassword(current password) = %v, want InvalidArgument", err)
+	}
+
+	newPassword := "saffron-Harbor-42-comet"
+
+	if err := s.ResetPassword(ctx, token, newPassword); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.ResetPassword(ctx, token, "juniper-Castle-19-meadow"); err == nil {
+		t.Error("ResetPassword with a used token = nil, want an error")
+	}
+
+	if _, err := s.Login(ctx, user.Email, testPassword, "203.0.113.7"); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Login(old password) = %v, want InvalidArgument", err)
+	}
+
+	if _, err := s.Login(ctx, user.Email, newPassword, "203.0.113.7"); err != nil {
+		t.Errorf("Login(new password) = %v, want nil", err)
+	}
+}
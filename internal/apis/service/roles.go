@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/store"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/rbac"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errUnknownRole = errors.New("unknown role")
+
+// ChangeUserRole moves userID to role on behalf of actor, keeping at least one admin.
+func (s *Service) ChangeUserRole(ctx context.Context, actor rbac.Caller, userID int64, role, reason string) error {
+	if _, ok := rbac.ParseRole(role); !ok {
+		return apperrors.BadRequestHidden(errUnknownRole, "unknown role "+role)
+	}
+
+	change := &models.RoleChange{
+		UserID:    userID,
+		NewRole:   role,
+		ChangedBy: actor.UserID,
+		Reason:    reason,
+	}
+
+	err := s.manageUser(ctx, actor, userID, func(tx *store.Store) error {
+		return tx.ChangeUserRole(ctx, change)
+	})
+
+	switch {
+	case errors.Is(err, store.ErrLastAdmin):
+		return status.Error(codes.FailedPrecondition, store.ErrLastAdmin.Error())
+	case err != nil:
+		return err
+	}
+
+	s.invalidateUser(ctx, userID)
+
+	return nil
+}
+
+// manageUser runs change in a transaction once actor is found to outrank userID, with the target's row
+// locked until it commits. Users acting on their own account need no rank.
+func (s *Service) manageUser(ctx context.Context, actor rbac.Caller, userID int64, change func(tx *store.Store) error) error {
+	err := s.store.WithTx(ctx, func(tx *store.Store) error {
+		if actor.UserID != userID {
+			if err := tx.LockManagedUser(ctx, userID, actor.Role); err != nil {
+				return err
+			}
+		}
+
+		return change(tx)
+	})
+	if errors.Is(err, store.ErrTargetNotOutranked) {
+		return status.Error(codes.PermissionDenied, store.ErrTargetNotOutranked.Error())
+	}
+
+	return err
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/rbac"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestAdminMutationsRequireRank(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	moderator := s.createTestUserWithRole(t, "Mia Moderator", string(rbac.RoleModerator))
+	actor := rbac.Caller{UserID: moderator.ID, Role: rbac.RoleModerator}
+
+	bio := "edited"
+
+	mutations := []struct {
+		name   string
+		mutate func(target *models.User) error
+	}{
+		{name: "update", mutate: func(target *models.User) error {
+			return s.UpdateUser(ctx, actor, target.ID, &models.UpdateUser{Bio: &bio})
+		}},
+		{name: "password", mutate: func(target *models.User) error {
+			return s.UpdateUserPassword(ctx, actor, target.ID, "saffron-Harbor-42-comet")
+		}},
+		{name: "delete", mutate: func(target *models.User) error {
+			return s.DeleteUser(ctx, actor, target.ID)
+		}},
+		{name: "confirm", mutate: func(target *models.User) error {
+			return s.ConfirmUser(ctx, actor, target.ID)
+		}},
+		{name: "role", mutate: func(target *models.User) error {
+			return s.ChangeUserRole(ctx, actor, target.ID, string(rbac.RoleUser), "test")
+		}},
+		{name: "restore", mutate: func(target *models.User) error {
+			return s.RestoreUser(ctx, actor, target.ID)
+		}},
+		{name: "erase", mutate: func(target *models.User) error {
+			_, err := s.EraseUser(ctx, actor, target.ID, "", "")
+			return err
+		}},
+		{name: "lockout", mutate: func(target *models.User) error {
+			return s.ClearLoginLockout(ctx, actor, target.Email, "")
+		}},
+	}
+
+	targets := []*models.User{
+		s.createTestUserWithRole(t, "Ada Admin", string(rbac.RoleAdmin)),
+		s.createTestUserWithRole(t, "Max Moderator", string(rbac.RoleModerator)),
+	}
+
+	for _, target := range targets {
+		for _, tt := range mutations {
+			t.Run(target.Role+"/"+tt.name, func(t *testing.T) {
+				if err := tt.mutate(target); status.Code(err) != codes.PermissionDenied {
+					t.Fatalf("err = %v, want PermissionDenied", err)
+				}
+			})
+		}
+	}
+
+	for _, target := range targets {
+		got, err := s.store.GetUserByID(ctx, int(target.ID))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.Role != target.Role || (got.Bio != nil && *got.Bio == bio) {
+			t.Errorf("%s was modified by a moderator: %+v", target.Role, got)
+		}
+	}
+}
+
+func TestAdminMutationsOnLowerRoles(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	moderator := s.createTestUserWithRole(t, "Mia Moderator", string(rbac.RoleModerator))
+	target := s.createTestUser(t, "Uma User")
+
+	bio := "edited"
+	if err := s.UpdateUser(ctx, rbac.Caller{UserID: moderator.ID, Role: rbac.RoleModerator}, target.ID, &models.UpdateUser{Bio: &bio}); err != nil {
+		t.Fatalf("moderator updating a user: %v", err)
+	}
+
+	admin := s.createTestUserWithRole(t, "Ada Admin", string(rbac.RoleAdmin))
+	other := s.createTestUserWithRole(t, "Ola Admin", string(rbac.RoleAdmin))
+
+	// Admins manage each other, or no admin could ever be demoted.
+	if err := s.ChangeUserRole(ctx, rbac.Caller{UserID: admin.ID, Role: rbac.RoleAdmin}, other.ID, string(rbac.RoleUser), "test"); err != nil {
+		t.Fatalf("admin demoting an admin: %v", err)
+	}
+}
+
+func TestChangeUserRoleKeepsLastAdmin(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	admin := s.createTestUserWithRole(t, "Ada Admin", string(rbac.RoleAdmin))
+	actor := rbac.Caller{UserID: admin.ID, Role: rbac.RoleAdmin}
+
+	if err := s.ChangeUserRole(ctx, actor, admin.ID, string(rbac.RoleUser), "test"); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("demoting the last admin = %v, want FailedPrecondition", err)
+	}
+
+	if err := s.ChangeUserRole(ctx, actor, admin.ID, "superuser", "test"); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ChangeUserRole(unknown role) = %v, want InvalidArgument", err)
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/pagination"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/rbac"
+	"strings"
+	"unicode"
+)
+
+const (
+	highlightStart = "<mark>"
+	highlightEnd   = "</mark>"
+)
+
+// SearchUsers finds public profiles for anyone, but only callers allowed to list users can find them by
+// email or see the addresses.
+func (s *Service) SearchUsers(ctx context.Context, caller rbac.Caller, query string, limit int, pageToken string) ([]*models.UserSearchHit, string, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, "", nil
+	}
+
+	switch {
+	case limit <= 0:
+		limit = defaultPageSize
+	case limit > maxPageSize:
+		limit = maxPageSize
+	}
+
+	var cursor models.SearchCursor
+	if pageToken != "" {
+		if err := s.pageTokens.Decode(pageToken, &cursor); err != nil {
+			return nil, "", apperrors.BadRequestHidden(err, "invalid page token")
+		}
+
+		if cursor.Query != query {
+			return nil, "", apperrors.BadRequestHidden(pagination.ErrInvalidToken, "page token does not match search query")
+		}
+	}
+
+	hits, err := s.store.SearchUsers(ctx, query, limit+1, cursor.Offset, caller.Role.Can(rbac.PermUsersList))
+	if err != nil {
+		return nil, "", err
+	}
+
+	var nextPageToken string
+	if len(hits) > limit {
+		hits = hits[:limit]
+
+		nextPageToken, err = s.pageTokens.Encode(models.SearchCursor{Query: query, Offset: cursor.Offset + limit})
+		if err != nil {
+			return nil, "", apperrors.Internal(err)
+		}
+	}
+
+	for _, hit := range hits {
+		hit.Highlight = highlight(matchedValue(hit), query)
+	}
+
+	return hits, nextPageToken, nil
+}
+
+func matchedValue(hit *models.UserSearchHit) string {
+	switch hit.MatchedField {
+	case models.SearchFieldEmail:
+		return hit.User.Email
+	case models.SearchFieldSlug:
+		return hit.User.Slug
+	default:
+		return hit.User.FullName
+	}
+}
+
+// highlight wraps every case-insensitive occurrence of the query words in value.
+// Typo-tolerant matches without an exact occurrence are returned unchanged.
+func highlight(value, query string) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '@' && r != '.'
+	})
+
+	runes := []rune(value)
+	lower := []rune(strings.ToLower(value))
+	marked := make([]bool, len(runes))
+
+	for _, word := range words {
+		needle := []rune(word)
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if string(lower[i:i+len(needle)]) == word {
+				for j := i; j < i+len(needle); j++ {
+					marked[j] = true
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	for i, r := range runes {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			b.WriteString(highlightStart)
+		}
+
+		b.WriteRune(r)
+
+		if marked[i] && (i == len(runes)-1 || !marked[i+1]) {
+			b.WriteString(highlightEnd)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/rbac"
+	"testing"
+)
+
+func TestSearchUsersByEmail(t *testing.T) {
+	s := newTestService(t)
+	moderator := s.createTestUserWithRole(t, "Mod Erator", string(rbac.RoleModerator))
+
+	// The address shares nothing with the name, so only an email match can find it.
+	user, err := s.CreateUser(context.Background(), &models.UserWithPassword{
+		User:         &models.User{FullName: "Ana Martinez", Email: "zq7.contact@example.com"},
+		UserPassword: &models.UserPassword{PasswordHash: testPassword},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		caller    rbac.Caller
+		query     string
+		wantEmail string
+		wantHit   bool
+	}{
+		{name: "anonymous by email prefix", caller: rbac.Caller{Role: rbac.RoleGuest}, query: "zq7.cont"},
+		{name: "user by email prefix", caller: rbac.Caller{UserID: user.ID, Role: rbac.RoleUser}, query: "zq7.cont"},
+		{name: "moderator by email prefix", caller: rbac.Caller{UserID: moderator.ID, Role: rbac.RoleModerator}, query: "zq7.cont", wantHit: true, wantEmail: user.Email},
+		{name: "anonymous by name", caller: rbac.Caller{Role: rbac.RoleGuest}, query: "martinez", wantHit: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, _, err := s.SearchUsers(context.Background(), tt.caller, tt.query, 10, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var hit *models.UserSearchHit
+			for _, h := range hits {
+				if h.User.ID == user.ID {
+					hit = h
+				}
+			}
+
+			if (hit != nil) != tt.wantHit {
+				t.Fatalf("SearchUsers(%q) found the user = %v, want %v", tt.query, hit != nil, tt.wantHit)
+			}
+
+			for _, h := range hits {
+				if !tt.caller.Role.Can(rbac.PermUsersList) && (h.User.Email != "" || h.MatchedField == models.SearchFieldEmail) {
+					t.Errorf("hit %d exposes email %q matched on %s to %s", h.User.ID, h.User.Email, h.MatchedField, tt.caller.Role)
+				}
+			}
+
+			if hit != nil && hit.User.Email != tt.wantEmail {
+				t.Errorf("email = %q, want %q", hit.User.Email, tt.wantEmail)
+			}
+		})
+	}
+}
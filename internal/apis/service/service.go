@@ -2,100 +2,431 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
 	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/blob"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/cache"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/lockout"
 	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/store"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/breach"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/config"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/metrics"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/mfa"
 	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/notifications"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/pagination"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/passwords"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/rbac"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+
+	// cacheLoadTimeout bounds a store read shared by readThrough callers, which no caller can cancel.
+	cacheLoadTimeout = 5 * time.Second
 )
 
 type Service struct {
-	store *store.Store
+	store      *store.Store
+	cache      cache.Cache
+	pageTokens *pagination.Codec
+	notifier   notifications.Notifier
+	lockout    *lockout.Guard
+	resends    *lockout.Limiter
+	mfaCipher  *mfa.Cipher
+	passwords  *passwords.Hasher
+	hashPool   *passwords.Pool
+	policy     *passwords.Policy
+	breaches   breach.Checker
+	metrics    *metrics.Metrics
+	blobs      blob.Store
+	dummyHash  func() (string, error)
+	group      singleflight.Group
+	cfg        *config.Config
+	logger     *zap.Logger
 }
 
-func NewService(store *store.Store) *Service {
-	return &Service{
-		store: store,
+func NewService(store *store.Store, cache cache.Cache, pageTokens *pagination.Codec, notifier notifications.Notifier, lockout *lockout.Guard, resends *lockout.Limiter, mfaCipher *mfa.Cipher, passwords *passwords.Hasher, hashPool *passwords.Pool, policy *passwords.Policy, breaches breach.Checker, metrics *metrics.Metrics, blobs blob.Store, cfg *config.Config, logger *zap.Logger) *Service {
+	s := &Service{
+		store:      store,
+		cache:      cache,
+		pageTokens: pageTokens,
+		notifier:   notifier,
+		lockout:    lockout,
+		resends:    resends,
+		mfaCipher:  mfaCipher,
+		passwords:  passwords,
+		hashPool:   hashPool,
+		policy:     policy,
+		breaches:   breaches,
+		metrics:    metrics,
+		blobs:      blobs,
+		cfg:        cfg,
+		logger:     logger,
 	}
+
+	s.dummyHash = sync.OnceValues(func() (string, error) {
+		return s.passwords.Hash(rand.Text())
+	})
+
+	return s
 }
 
 func (s *Service) GetUserByIdentifier(ctx context.Context, identifier string) (*models.User, error) {
+	user, err := s.getUserByIdentifier(ctx, identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.applySuspensionVisibility(ctx, user)
+}
+
+func (s *Service) getUserByIdentifier(ctx context.Context, identifier string) (*models.User, error) {
 	id, ok := extractID(identifier)
 	if ok {
-		return s.store.GetUserByID(ctx, id)
+		return s.readThrough(ctx, "id:"+identifier,
+			func(ctx context.Context) (*models.User, error) { return s.cache.GetUserByID(ctx, int64(id)) },
+			func(ctx context.Context) (*models.User, error) { return s.store.GetUserByID(ctx, id) },
+		)
 	}
-	return s.store.GetUserBySlug(ctx, identifier)
+
+	return s.readThrough(ctx, "slug:"+identifier,
+		func(ctx context.Context) (*models.User, error) { return s.cache.GetUserBySlug(ctx, identifier) },
+		func(ctx context.Context) (*models.User, error) { return s.store.GetUserBySlug(ctx, identifier) },
+	)
 }
 
-func (s *Service) GetUserByEmail(ctx context.Context, email, password string) (*models.User, error) {
+func (s *Service) ListUsers(ctx context.Context, filter *models.ListUsersFilter, pageToken string) ([]*models.User, string, error) {
+	switch {
+	case filter.Limit <= 0:
+		filter.Limit = defaultPageSize
+	case filter.Limit > maxPageSize:
+		filter.Limit = maxPageSize
+	}
+
+	if pageToken != "" {
+		var cursor models.UsersCursor
+		if err := s.pageTokens.Decode(pageToken, &cursor); err != nil {
+			return nil, "", apperrors.BadRequestHidden(err, "invalid page token")
+		}
+
+		if cursor.SortBy != filter.SortBy || cursor.Descending != filter.Descending {
+			return nil, "", apperrors.BadRequestHidden(pagination.ErrInvalidToken, "page token does not match requested sorting")
+		}
+
+		filter.Cursor = &cursor
+	}
+
+	limit := filter.Limit
+	filter.Limit = limit + 1
+
+	list, err := s.store.ListUsers(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(list) <= limit {
+		return list, "", nil
+	}
+
+	list = list[:limit]
+
+	nextPageToken, err := s.pageTokens.Encode(store.UsersCursorFor(list[limit-1], filter.SortBy, filter.Descending))
+	if err != nil {
+		return nil, "", apperrors.Internal(err)
+	}
+
+	return list, nextPageToken, nil
+}
+
+func (s *Service) GetUserByEmail(ctx context.Context, email, password, clientIP string) (*models.User, error) {
+	if err := s.lockout.Check(ctx, email, clientIP); err != nil {
+		s.metrics.Login(metrics.ResultLocked)
+		return nil, err
+	}
+
 	user, err := s.store.GetUserByEmail(ctx, email)
+	if status.Code(err) == codes.NotFound {
+		// An unknown email costs the same hash comparison and counts as a failure like a wrong password,
+		// so neither the response nor its timing reveals whether the account exists.
+		if _, err = s.compareDummyPassword(ctx, password); err != nil {
+			return nil, err
+		}
+
+		s.lockout.Fail(ctx, email, clientIP, 0)
+		s.metrics.Login(metrics.ResultFailed)
+		return nil, errInvalidCredentials
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+	match, err := s.comparePassword(ctx, user.PasswordHash, password)
 	if err != nil {
-		return nil, apperrors.BadRequestHidden(err, "invalid password")
+		return nil, err
+	}
+
+	if !match {
+		s.lockout.Fail(ctx, email, clientIP, user.ID)
+		s.metrics.Login(metrics.ResultFailed)
+		return nil, errInvalidCredentials
 	}
 
+	s.upgradePasswordHash(ctx, user.ID, user.PasswordHash, password)
+
+	// Checked only after the password so the suspension is not revealed to someone guessing it.
+	if user.Suspended {
+		s.metrics.Login(metrics.ResultSuspended)
+		return nil, suspendedError(user.User)
+	}
+
+	s.metrics.Login(metrics.ResultSucceeded)
+	s.invalidateUser(ctx, user.ID)
+
 	return user.User, nil
 }
 
+func (s *Service) GetLoginLockout(ctx context.Context, email, clientIP string) (account, client *lockout.Status, err error) {
+	account, client, err = s.lockout.Status(ctx, email, clientIP)
+	if err != nil {
+		return nil, nil, apperrors.Internal(err)
+	}
+
+	return account, client, nil
+}
+
+// ClearLoginLockout lifts the lockout of email and clientIP. Clearing the lockout of an account needs a
+// role above its owner's, like any other change to it.
+func (s *Service) ClearLoginLockout(ctx context.Context, actor rbac.Caller, email, clientIP string) error {
+	clearLockout := func(*store.Store) error {
+		if err := s.lockout.Clear(ctx, email, clientIP); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		return nil
+	}
+
+	if email == "" {
+		return clearLockout(nil)
+	}
+
+	userID, _, found, err := s.store.FindUserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return clearLockout(nil)
+	}
+
+	return s.manageUser(ctx, actor, userID, clearLockout)
+}
+
 func (s *Service) CreateUser(ctx context.Context, user *models.UserWithPassword) (*models.User, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(user.PasswordHash), bcrypt.DefaultCost)
+	// The slug only exists once the user is prepared, and the password must not contain it either.
+	user = user.PrepareUser()
+
+	violations, err := s.passwordViolations(user.PasswordHash, user.Email, user.FullName, user.Slug)
 	if err != nil {
-		return nil, apperrors.Internal(err)
+		return nil, err
 	}
 
-	user.PasswordHash = string(hash)
+	if len(violations) > 0 {
+		return nil, passwordPolicyError(violations)
+	}
+
+	hash, err := s.hashPassword(ctx, user.PasswordHash)
+	if err != nil {
+		return nil, err
+	}
+
+	user.PasswordHash = hash
+
+	var newUser *models.User
+
+	err = s.store.WithTx(ctx, func(tx *store.Store) error {
+		newUser, err = tx.CreateUser(ctx, user)
+		if err != nil {
+			return err
+		}
 
-	newUser, err := s.store.CreateUser(ctx, user.PrepareUser())
+		return tx.AddPasswordHistory(ctx, newUser.ID, user.PasswordHash)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	err = s.store.AddPasswordHistory(ctx, newUser.ID, user.PasswordHash)
+	s.metrics.Registered()
 
-	return newUser, err
+	// The account is already created; a failed email only means the user has to ask for a resend.
+	if err = s.issueVerificationToken(ctx, newUser.ID, newUser.Email); err != nil {
+		s.logger.Warn("users-service | failed to issue verification token", zap.Int64("user_id", newUser.ID), zap.Error(err))
+	}
+
+	return newUser, nil
 }
 
-func (s *Service) ConfirmUser(ctx context.Context, userID int64) error {
-	return s.store.ConfirmUser(ctx, userID)
+func (s *Service) ConfirmUser(ctx context.Context, actor rbac.Caller, userID int64) error {
+	err := s.manageUser(ctx, actor, userID, func(tx *store.Store) error {
+		return tx.ConfirmUser(ctx, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateUser(ctx, userID)
+
+	return nil
 }
 
-func (s *Service) UpdateUser(ctx context.Context, userID int64, user *models.UpdateUser) error {
-	return s.store.UpdateUser(ctx, int(userID), user.PrepareUser())
+func (s *Service) UpdateUser(ctx context.Context, actor rbac.Caller, userID int64, user *models.UpdateUser) error {
+	err := s.manageUser(ctx, actor, userID, func(tx *store.Store) error {
+		return tx.UpdateUser(ctx, int(userID), user.PrepareUser())
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateUser(ctx, userID)
+
+	return nil
 }
 
-func (s *Service) UpdateUserPassword(ctx context.Context, userID int64, password string) error {
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+func (s *Service) UpdateUserPassword(ctx context.Context, actor rbac.Caller, userID int64, password string) error {
+	passwordHash, err := s.newPasswordHash(ctx, userID, password)
+	if err != nil {
+		return err
+	}
+
+	err = s.manageUser(ctx, actor, userID, func(tx *store.Store) error {
+		return setPassword(ctx, tx, userID, passwordHash)
+	})
 	if err != nil {
-		return apperrors.Internal(err)
+		return err
 	}
 
-	passwordHash := string(hash)
+	s.invalidateUser(ctx, userID)
+
+	return nil
+}
 
-	histories, err := s.store.GetPasswordHistory(ctx, userID)
+// newPasswordHash hashes password after checking it against the password policy and the user's
+// recent passwords. All violations are reported together.
+func (s *Service) newPasswordHash(ctx context.Context, userID int64, password string) (string, error) {
+	user, err := s.store.GetUserByID(ctx, int(userID))
 	if err != nil {
-		return apperrors.Internal(err)
+		return "", err
 	}
 
-	for _, history := range histories {
-		if err = bcrypt.CompareHashAndPassword([]byte(history.PasswordHash), []byte(password)); err == nil {
-			return apperrors.BadRequestHidden(err, "this password is already used")
-		}
+	violations, err := s.passwordViolations(password, user.Email, user.FullName, user.Slug)
+	if err != nil {
+		return "", err
 	}
 
-	err = s.store.UpdatePassword(ctx, int(userID), passwordHash)
+	reused, err := s.passwordReused(ctx, userID, password)
+	if err != nil {
+		return "", err
+	}
+
+	if reused {
+		violations = append(violations, passwords.Violation{Rule: passwords.RuleReused, Message: "this password is already used"})
+	}
+
+	if len(violations) > 0 {
+		return "", passwordPolicyError(violations)
+	}
+
+	hash, err := s.hashPassword(ctx, password)
+	if err != nil {
+		return "", err
+	}
+
+	return hash, nil
+}
+
+// setPassword stores the new password and signs the user out everywhere, since a password change
+// is often a response to a compromised account.
+func setPassword(ctx context.Context, tx *store.Store, userID int64, passwordHash string) error {
+	if err := tx.UpdatePassword(ctx, int(userID), passwordHash); err != nil {
+		return err
+	}
+
+	if err := tx.AddPasswordHistory(ctx, userID, passwordHash); err != nil {
+		return err
+	}
+
+	_, err := tx.RevokeAllSessions(ctx, userID, models.SessionRevokedPasswordChanged)
+	return err
+}
+
+func (s *Service) DeleteUser(ctx context.Context, actor rbac.Caller, userID int64) error {
+	err := s.manageUser(ctx, actor, userID, func(tx *store.Store) error {
+		return tx.DeleteUser(ctx, int(userID))
+	})
 	if err != nil {
 		return err
 	}
 
-	return s.store.AddPasswordHistory(ctx, userID, passwordHash)
+	s.metrics.Deleted()
+	s.invalidateUser(ctx, userID)
+
+	return nil
+}
+
+// readThrough serves a user from the cache and falls back to Postgres on a miss or
+// when the cache is unavailable. Concurrent misses for the same key share one query.
+func (s *Service) readThrough(ctx context.Context, key string, fromCache, fromStore func(context.Context) (*models.User, error)) (*models.User, error) {
+	user, err := fromCache(ctx)
+	switch {
+	case err == nil:
+		return user, nil
+	case !errors.Is(err, cache.ErrMiss):
+		s.logger.Warn("users-service | cache read failed, falling back to postgres", zap.String("key", key), zap.Error(err))
+	}
+
+	// The load is shared by every caller waiting on key, so it must not fail because the first one gave up;
+	// each caller still stops waiting once its own context is done.
+	results := s.group.DoChan(key, func() (any, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cacheLoadTimeout)
+		defer cancel()
+
+		user, err := fromStore(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = s.cache.SetUser(loadCtx, user); err != nil {
+			s.logger.Warn("users-service | cache write failed", zap.String("key", key), zap.Error(err))
+		}
+
+		return user, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	case result := <-results:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		return result.Val.(*models.User), nil
+	}
 }
 
-func (s *Service) DeleteUser(ctx context.Context, userID int64) error {
-	return s.store.DeleteUser(ctx, int(userID))
+func (s *Service) invalidateUser(ctx context.Context, userID int64) {
+	if err := s.cache.InvalidateUser(ctx, userID); err != nil {
+		s.logger.Warn("users-service | cache invalidation failed", zap.Int64("user_id", userID), zap.Error(err))
+	}
 }
 
 func extractID(identifier string) (id int, ok bool) {
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/blob"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/cache"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/lockout"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/store"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/store/storetest"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/breach"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/config"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/events"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/metrics"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/mfa"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/pagination"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/passwords"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/rbac"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testPassword satisfies the default password policy for every account created by createTestUser.
+const testPassword = "tangerine-Velvet-87-orbit"
+
+type testService struct {
+	*Service
+	notifier *recordingNotifier
+}
+
+func newTestService(t *testing.T) *testService {
+	t.Helper()
+
+	pool := storetest.NewPool(t)
+	logger := zap.NewNop()
+
+	cfg := &config.Config{
+		Verification:   config.VerificationConfig{TokenTTL: time.Hour, ResendCooldown: time.Minute, MaxPerDay: 5},
+		PasswordReset:  config.PasswordResetConfig{TokenTTL: time.Hour, ResendCooldown: time.Minute},
+		PasswordPolicy: config.PasswordPolicyConfig{MinLength: 10, MaxLength: 128, MinCharacterClasses: 2, MinEntropyBits: 45, HistoryDepth: 5},
+		Lockout:        config.LockoutConfig{MaxAccountAttempts: 3, MaxIPAttempts: 20, Window: time.Minute, Duration: time.Minute, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+		Session:        config.SessionConfig{RefreshTokenTTL: time.Hour},
+		MFA:            config.MFAConfig{Issuer: "test", ChallengeTTL: time.Minute, MaxChallengeAttempts: 5, RecoveryCodes: 4},
+		Suspension:     config.SuspensionConfig{Visibility: config.SuspensionVisibilityMark},
+		Purge:          config.PurgeConfig{GracePeriod: time.Hour, BatchSize: 10, Mode: config.PurgeModeAnonymize},
+	}
+
+	hasher, err := passwords.NewHasher(passwords.AlgorithmBcrypt, bcrypt.MinCost, passwords.Argon2Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hashPool := passwords.NewPool(2, 16, func(time.Duration) {})
+	t.Cleanup(hashPool.Stop)
+
+	cipher, err := mfa.NewCipher(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	breaches, err := breach.Open("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blobs, err := blob.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counter := lockout.NewMemoryCounter()
+	notifier := &recordingNotifier{tokens: make(map[string]string)}
+
+	s := NewService(
+		store.NewStore(pool),
+		cache.NewMemoryCache(time.Minute),
+		pagination.NewCodec([]byte("test")),
+		notifier,
+		lockout.NewGuard(counter, cfg.Lockout, events.NewLogPublisher(logger), logger),
+		lockout.NewLimiter(counter, "verification", cfg.Verification.ResendCooldown, cfg.Verification.MaxPerDay, 24*time.Hour),
+		cipher,
+		hasher,
+		hashPool,
+		passwords.NewPolicy(passwords.PolicyParams{
+			MinLength:           cfg.PasswordPolicy.MinLength,
+			MaxLength:           cfg.PasswordPolicy.MaxLength,
+			MinCharacterClasses: cfg.PasswordPolicy.MinCharacterClasses,
+			MinEntropyBits:      cfg.PasswordPolicy.MinEntropyBits,
+		}),
+		breaches,
+		metrics.New(pool),
+		blobs,
+		cfg,
+		logger,
+	)
+
+	return &testService{Service: s, notifier: notifier}
+}
+
+// createTestUser registers a user named name with testPassword.
+func (s *testService) createTestUser(t *testing.T, name string) *models.User {
+	t.Helper()
+
+	user, err := s.CreateUser(context.Background(), &models.UserWithPassword{
+		User:         &models.User{FullName: name, Email: strings.ToLower(strings.ReplaceAll(name, " ", ".")) + "@example.com"},
+		UserPassword: &models.UserPassword{PasswordHash: testPassword},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return user
+}
+
+// createTestUserWithRole registers a user and moves them to role.
+func (s *testService) createTestUserWithRole(t *testing.T, name, role string) *models.User {
+	t.Helper()
+
+	user := s.createTestUser(t, name)
+
+	if err := s.store.ChangeUserRole(context.Background(), &models.RoleChange{UserID: user.ID, NewRole: role, Reason: "test"}); err != nil {
+		t.Fatal(err)
+	}
+
+	user.Role = role
+
+	return user
+}
+
+// recordingNotifier keeps the last token sent to every address instead of mailing it.
+type recordingNotifier struct {
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+func (n *recordingNotifier) SendEmailVerification(_ context.Context, email, token string) error {
+	n.record("verify:"+email, token)
+	return nil
+}
+
+func (n *recordingNotifier) SendPasswordReset(_ context.Context, email, token string) error {
+	n.record("reset:"+email, token)
+	return nil
+}
+
+func (n *recordingNotifier) record(key, token string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.tokens[key] = token
+}
+
+func (n *recordingNotifier) token(key string) string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.tokens[key]
+}
+
+func TestLogin(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	user := s.createTestUser(t, "Lena Login")
+
+	result, err := s.Login(ctx, user.Email, testPassword, "203.0.113.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.User == nil || result.User.ID != user.ID || result.Challenge != nil {
+		t.Errorf("Login = %+v, want the user without a challenge", result)
+	}
+
+	// A wrong password and an unknown email fail the same way, so neither reveals whether the account exists.
+	for _, email := range []string{user.Email, "nobody@example.com"} {
+		if _, err = s.Login(ctx, email, "wrong-Password-42-x", "203.0.113.7"); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Login(%s, wrong password) = %v, want InvalidArgument", email, err)
+		}
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	user := s.createTestUser(t, "Lars Locked")
+	admin := s.createTestUserWithRole(t, "Ada Admin", "admin")
+
+	for range s.cfg.Lockout.MaxAccountAttempts {
+		if _, err := s.Login(ctx, user.Email, "wrong-Password-42-x", "203.0.113.7"); status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("Login(wrong password) = %v, want InvalidArgument", err)
+		}
+	}
+
+	// Once locked, even the right password is refused, from any address.
+	if _, err := s.Login(ctx, user.Email, testPassword, "198.51.100.9"); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Login of a locked account = %v, want ResourceExhausted", err)
+	}
+
+	if err := s.ClearLoginLockout(ctx, rbac.Caller{UserID: admin.ID, Role: rbac.RoleAdmin}, user.Email, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Login(ctx, user.Email, testPassword, "198.51.100.9"); err != nil {
+		t.Errorf("Login after the lockout was cleared = %v, want nil", err)
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/store"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/tokens"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// StartSession opens a session for a user who just logged in and issues its first refresh token.
+func (s *Service) StartSession(ctx context.Context, userID int64, userAgent, clientIP string) (*models.IssuedSession, error) {
+	token, hash, err := tokens.Generate()
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	session := &models.Session{
+		UserID:    userID,
+		UserAgent: userAgent,
+		IP:        clientIP,
+		ExpiresAt: time.Now().Add(s.cfg.Session.RefreshTokenTTL),
+	}
+
+	if err = s.store.CreateSession(ctx, session, hash); err != nil {
+		return nil, err
+	}
+
+	return &models.IssuedSession{Session: session, RefreshToken: token}, nil
+}
+
+// RefreshSession rotates the refresh token and returns the session owner, so the caller can mint a
+// new access token. A suspended user's token is still rotated but the new one is withheld, which
+// leaves the session unusable.
+func (s *Service) RefreshSession(ctx context.Context, refreshToken, userAgent, clientIP string) (*models.IssuedSession, *models.User, error) {
+	token, hash, err := tokens.Generate()
+	if err != nil {
+		return nil, nil, apperrors.Internal(err)
+	}
+
+	expiresAt := time.Now().Add(s.cfg.Session.RefreshTokenTTL)
+
+	session, err := s.store.RotateRefreshToken(ctx, tokens.Hash(refreshToken), hash, expiresAt, userAgent, clientIP)
+
+	switch {
+	case errors.Is(err, store.ErrRefreshTokenReused):
+		s.logger.Warn("users-service | refresh token reused, session revoked", zap.String("client_ip", clientIP))
+		return nil, nil, status.Error(codes.Unauthenticated, store.ErrInvalidRefreshToken.Error())
+	case errors.Is(err, store.ErrInvalidRefreshToken):
+		return nil, nil, status.Error(codes.Unauthenticated, store.ErrInvalidRefreshToken.Error())
+	case err != nil:
+		return nil, nil, err
+	}
+
+	user, err := s.store.GetUserByID(ctx, int(session.UserID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if user.Suspended {
+		return nil, nil, suspendedError(user)
+	}
+
+	return &models.IssuedSession{Session: session, RefreshToken: token}, user, nil
+}
+
+func (s *Service) ListSessions(ctx context.Context, userID int64) ([]*models.Session, error) {
+	return s.store.ListSessions(ctx, userID)
+}
+
+func (s *Service) RevokeSession(ctx context.Context, userID, sessionID int64) error {
+	return s.store.RevokeSession(ctx, userID, sessionID, models.SessionRevokedByUser)
+}
+
+func (s *Service) RevokeAllSessions(ctx context.Context, userID int64) (int64, error) {
+	return s.store.RevokeAllSessions(ctx, userID, models.SessionRevokedByUser)
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/store"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/tokens"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// RequestEmailVerification issues a fresh verification token. The resend limits apply per email before
+// the account is looked up, and unknown and already verified emails are accepted silently, so every
+// email gets the same answer and the endpoint cannot be used to probe for accounts.
+func (s *Service) RequestEmailVerification(ctx context.Context, email string) error {
+	if err := s.limitVerificationRequests(ctx, email); err != nil {
+		return err
+	}
+
+	userID, verified, found, err := s.store.FindUserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	if !found || verified {
+		return nil
+	}
+
+	err = s.issueVerificationToken(ctx, userID, email)
+	if status.Code(err) == codes.ResourceExhausted {
+		// The per-account limit can only trip when the per-email counters were lost, and answering it
+		// would tell this email apart from one without an account.
+		return nil
+	}
+
+	return err
+}
+
+func (s *Service) limitVerificationRequests(ctx context.Context, email string) error {
+	retryAfter, exhausted, err := s.resends.Allow(ctx, email)
+	if err != nil {
+		s.logger.Warn("users-service | verification request limit check failed", zap.Error(err))
+		return nil
+	}
+
+	switch {
+	case exhausted:
+		return status.Error(codes.ResourceExhausted, "too many verification emails requested, try again tomorrow")
+	case retryAfter > 0:
+		return status.Errorf(codes.ResourceExhausted, "verification email was sent recently, retry after %s", retryAfter.Round(time.Second))
+	}
+
+	return nil
+}
+
+func (s *Service) VerifyEmail(ctx context.Context, token string) error {
+	var userID int64
+
+	err := s.store.WithTx(ctx, func(tx *store.Store) error {
+		var err error
+
+		userID, err = tx.ConsumeVerificationToken(ctx, tokens.Hash(token))
+		if err != nil {
+			return err
+		}
+
+		return tx.ConfirmUser(ctx, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.metrics.Verified()
+	s.invalidateUser(ctx, userID)
+
+	return nil
+}
+
+func (s *Service) issueVerificationToken(ctx context.Context, userID int64, email string) error {
+	cfg := s.cfg.Verification
+	now := time.Now()
+
+	count, latest, err := s.store.VerificationTokensSince(ctx, userID, now.Add(-24*time.Hour))
+	if err != nil {
+		return err
+	}
+
+	if count >= cfg.MaxPerDay {
+		return status.Error(codes.ResourceExhausted, "too many verification emails requested, try again tomorrow")
+	}
+
+	if latest != nil && now.Sub(*latest) < cfg.ResendCooldown {
+		retryAfter := cfg.ResendCooldown - now.Sub(*latest)
+		return status.Errorf(codes.ResourceExhausted, "verification email was sent recently, retry after %s", retryAfter.Round(time.Second))
+	}
+
+	token, hash, err := tokens.Generate()
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	if err = s.store.CreateVerificationToken(ctx, userID, hash, now.Add(cfg.TokenTTL)); err != nil {
+		return err
+	}
+
+	if err = s.notifier.SendEmailVerification(ctx, email, token); err != nil {
+		s.logger.Error("users-service | failed to send verification email", zap.Int64("user_id", userID), zap.Error(err))
+		return apperrors.Internal(err)
+	}
+
+	return nil
+}
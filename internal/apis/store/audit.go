@@ -0,0 +1,31 @@
+package store
+
+import (
+	"context"
+	"github.com/Brain-Wave-Ecosystem/go-common/pkg/dbx"
+	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+)
+
+func (s *Store) RecordAccessDenial(ctx context.Context, denial *models.AccessDenial) error {
+	var clientIP *string
+	if denial.ClientIP != "" {
+		clientIP = &denial.ClientIP
+	}
+
+	builder := dbx.StatementBuilder.
+		Insert("users_access_denials").
+		Columns("user_id", "role", "method", "reason", "client_ip").
+		Values(denial.UserID, denial.Role, denial.Method, denial.Reason, clientIP)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	if _, err = s.db.Exec(ctx, query, args...); err != nil {
+		return apperrors.Internal(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"github.com/Brain-Wave-Ecosystem/go-common/pkg/dbx"
+	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/events"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// EraseUser irreversibly strips the user's PII in place and from their outbox events, drops their
+// credentials, tokens and access denials, writes the erasure receipt and enqueues the event, all in one
+// transaction. The users row and its id are kept so references held by other services stay valid. Active
+// and soft-deleted accounts can be erased; erasing the only active admin fails with ErrLastAdmin.
+func (s *Store) EraseUser(ctx context.Context, userID, requestedBy int64) (*models.ErasureReceipt, error) {
+	var receipt *models.ErasureReceipt
+
+	err := s.WithTx(ctx, func(tx *Store) error {
+		role, active, err := tx.lockErasableUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if role == adminRole && active {
+			if err = tx.ensureAnotherAdmin(ctx); err != nil {
+				return err
+			}
+		}
+
+		if err = tx.deleteUserData(ctx, userID); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if err = tx.anonymizeUser(ctx, userID); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if err = tx.redactOutboxEvents(ctx, userID); err != nil {
+			return err
+		}
+
+		var requester *int64
+		if requestedBy != 0 {
+			requester = &requestedBy
+		}
+
+		builder := dbx.StatementBuilder.
+			Insert("users_erasures").
+			Columns("user_id", "requested_by").
+			Values(userID, requester).
+			Suffix("RETURNING id, erased_at")
+
+		query, args, err := builder.ToSql()
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		receipt = &models.ErasureReceipt{UserID: userID, RequestedBy: requester}
+
+		if err = tx.db.QueryRow(ctx, query, args...).Scan(&receipt.ID, &receipt.ErasedAt); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		event, err := events.UserErased(receipt)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if err = insertOutboxEvent(ctx, tx.db, event); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return receipt, nil
+}
+
+// lockErasableUser locks a user that has not been purged or erased yet and reports whether it is active.
+func (s *Store) lockErasableUser(ctx context.Context, userID int64) (role string, active bool, err error) {
+	builder := dbx.StatementBuilder.
+		Select("role", "deleted_at IS NULL").
+		From("users").
+		Where(squirrel.Eq{"id": userID}).
+		Where(squirrel.Eq{"purged_at": nil}).
+		Suffix("FOR UPDATE")
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return "", false, apperrors.Internal(err)
+	}
+
+	err = s.db.QueryRow(ctx, query, args...).Scan(&role, &active)
+
+	switch {
+	case dbx.IsNoRows(err):
+		return "", false, apperrors.NotFound("user", "id", userID)
+	case err != nil:
+		return "", false, apperrors.Internal(err)
+	}
+
+	return role, active, nil
+}
+
+// redactOutboxEvents rewrites the user's outbox events that carry PII, published or not, with the PII removed.
+func (s *Store) redactOutboxEvents(ctx context.Context, userID int64) error {
+	type storedEvent struct {
+		ID      int64
+		Type    string
+		Payload []byte
+	}
+
+	builder := dbx.StatementBuilder.
+		Select("id", "event_type", "payload").
+		From("users_outbox").
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Eq{"event_type": events.RedactableTypes}).
+		Suffix("FOR UPDATE")
+
+	stored, err := collect(ctx, s, builder, pgx.RowToStructByPos[storedEvent])
+	if err != nil {
+		return err
+	}
+
+	for _, event := range stored {
+		payload, err := events.Redact(event.Type, event.Payload)
+		if err != nil {
+			return apperrors.Internal(fmt.Errorf("redact outbox event %d: %w", event.ID, err))
+		}
+
+		query, args, err := dbx.StatementBuilder.
+			Update("users_outbox").
+			Set("payload", payload).
+			Where(squirrel.Eq{"id": event.ID}).
+			ToSql()
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if _, err = s.db.Exec(ctx, query, args...); err != nil {
+			return apperrors.Internal(err)
+		}
+	}
+
+	return nil
+}
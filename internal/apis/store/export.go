@@ -0,0 +1,156 @@
+package store
+
+import (
+	"context"
+	"github.com/Brain-Wave-Ecosystem/go-common/pkg/dbx"
+	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+// GetUserExport collects every row kept about the user, soft-deleted accounts included, inside one
+// repeatable-read transaction so the archive is a consistent snapshot.
+func (s *Store) GetUserExport(ctx context.Context, userID int64) (*models.UserExport, error) {
+	export := &models.UserExport{
+		Version:     models.UserExportVersion,
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	err := s.WithTx(ctx, func(tx *Store) error {
+		if _, err := tx.db.Exec(ctx, "SET TRANSACTION ISOLATION LEVEL REPEATABLE READ READ ONLY"); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		var err error
+
+		if export.Account, err = tx.exportAccount(ctx, userID); err != nil {
+			return err
+		}
+
+		passwordChanges := dbx.StatementBuilder.
+			Select("created_at").
+			From("users_password_history").
+			Where(squirrel.Eq{"user_id": userID}).
+			OrderBy("created_at")
+
+		if export.PasswordChanges, err = collect(ctx, tx, passwordChanges, pgx.RowTo[time.Time]); err != nil {
+			return err
+		}
+
+		roleChanges := dbx.StatementBuilder.
+			Select("old_role", "new_role", "changed_by", "reason", "created_at").
+			From("users_role_history").
+			Where(squirrel.Eq{"user_id": userID}).
+			OrderBy("created_at")
+
+		if export.RoleChanges, err = collect(ctx, tx, roleChanges, pgx.RowToAddrOfStructByPos[models.ExportRoleChange]); err != nil {
+			return err
+		}
+
+		if export.MFA, err = tx.exportMFA(ctx, userID); err != nil {
+			return err
+		}
+
+		verifications := dbx.StatementBuilder.
+			Select("created_at", "expires_at", "consumed_at").
+			From("users_verification_tokens").
+			Where(squirrel.Eq{"user_id": userID}).
+			OrderBy("created_at")
+
+		if export.EmailVerifications, err = collect(ctx, tx, verifications, pgx.RowToAddrOfStructByPos[models.ExportToken]); err != nil {
+			return err
+		}
+
+		resets := dbx.StatementBuilder.
+			Select("created_at", "expires_at", "consumed_at").
+			From("users_password_reset_tokens").
+			Where(squirrel.Eq{"user_id": userID}).
+			OrderBy("created_at")
+
+		if export.PasswordResets, err = collect(ctx, tx, resets, pgx.RowToAddrOfStructByPos[models.ExportToken]); err != nil {
+			return err
+		}
+
+		denials := dbx.StatementBuilder.
+			Select("method", "reason", "client_ip", "created_at").
+			From("users_access_denials").
+			Where(squirrel.Eq{"user_id": userID}).
+			OrderBy("created_at")
+
+		if export.AccessDenials, err = collect(ctx, tx, denials, pgx.RowToAddrOfStructByPos[models.ExportAccessDenial]); err != nil {
+			return err
+		}
+
+		sessions := dbx.StatementBuilder.
+			Select("user_agent", "ip", "created_at", "last_used_at", "expires_at", "revoked_at", "revoke_reason").
+			From("users_sessions").
+			Where(squirrel.Eq{"user_id": userID}).
+			OrderBy("created_at")
+
+		export.Sessions, err = collect(ctx, tx, sessions, pgx.RowToAddrOfStructByPos[models.ExportSession])
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+func (s *Store) exportAccount(ctx context.Context, userID int64) (*models.ExportAccount, error) {
+	builder := dbx.StatementBuilder.
+		Select("id", "email", "full_name", "slug", "avatar_url", "bio", "role", "is_verified", "profile_visibility",
+			"created_at", "updated_at", "last_login_at", "deleted_at", "suspended_at", "suspended_until", "suspension_reason").
+		From("users").
+		Where(squirrel.Eq{"id": userID}).
+		Where(squirrel.Eq{"purged_at": nil})
+
+	accounts, err := collect(ctx, s, builder, pgx.RowToAddrOfStructByPos[models.ExportAccount])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(accounts) == 0 {
+		return nil, apperrors.NotFound("user", "id", userID)
+	}
+
+	return accounts[0], nil
+}
+
+func (s *Store) exportMFA(ctx context.Context, userID int64) (*models.ExportMFA, error) {
+	builder := dbx.StatementBuilder.
+		Select("m.confirmed_at", "m.created_at", "COUNT(c.id) FILTER (WHERE c.used_at IS NULL)").
+		From("users_mfa m").
+		LeftJoin("users_mfa_recovery_codes c ON c.user_id = m.user_id").
+		Where(squirrel.Eq{"m.user_id": userID}).
+		GroupBy("m.user_id")
+
+	enrollments, err := collect(ctx, s, builder, pgx.RowToAddrOfStructByPos[models.ExportMFA])
+	if err != nil || len(enrollments) == 0 {
+		return nil, err
+	}
+
+	return enrollments[0], nil
+}
+
+// collect runs a select built with squirrel and maps every row with fn.
+func collect[T any](ctx context.Context, s *Store, builder squirrel.Sqlizer, fn pgx.RowToFunc[T]) ([]T, error) {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	items, err := pgx.CollectRows(rows, fn)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return items, nil
+}
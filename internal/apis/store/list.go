@@ -0,0 +1,150 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"github.com/Brain-Wave-Ecosystem/go-common/pkg/dbx"
+	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"github.com/Masterminds/squirrel"
+	"time"
+)
+
+// lastLoginSortExpr orders users that never logged in before everyone else while keeping the column comparable.
+const lastLoginSortExpr = "COALESCE(last_login_at, 'epoch'::timestamp)"
+
+// ListUsers returns up to filter.Limit users after filter.Cursor using keyset pagination on (sort column, id).
+func (s *Store) ListUsers(ctx context.Context, filter *models.ListUsersFilter) ([]*models.User, error) {
+	sortExpr, err := sortExpression(filter.SortBy)
+	if err != nil {
+		return nil, apperrors.BadRequest(err)
+	}
+
+	builder := dbx.StatementBuilder.
+		Select("id", "email", "avatar_url", "full_name", "slug", "bio", "last_login_at", "role", "is_verified", "created_at", "updated_at").
+		From("users").
+		Limit(uint64(filter.Limit))
+
+	if filter.Deleted {
+		builder = builder.Where(squirrel.NotEq{"deleted_at": nil})
+	} else {
+		builder = builder.Where(squirrel.Eq{"deleted_at": nil})
+	}
+
+	if filter.Role != nil {
+		builder = builder.Where(squirrel.Eq{"role": *filter.Role})
+	}
+	if filter.IsVerified != nil {
+		builder = builder.Where(squirrel.Eq{"is_verified": *filter.IsVerified})
+	}
+	if filter.CreatedAfter != nil {
+		builder = builder.Where(squirrel.GtOrEq{"created_at": *filter.CreatedAfter})
+	}
+	if filter.CreatedBefore != nil {
+		builder = builder.Where(squirrel.Lt{"created_at": *filter.CreatedBefore})
+	}
+	if filter.LastLoginAfter != nil {
+		builder = builder.Where(squirrel.GtOrEq{"last_login_at": *filter.LastLoginAfter})
+	}
+	if filter.LastLoginBefore != nil {
+		builder = builder.Where(squirrel.Lt{"last_login_at": *filter.LastLoginBefore})
+	}
+
+	direction, comparator := "ASC", ">"
+	if filter.Descending {
+		direction, comparator = "DESC", "<"
+	}
+
+	if filter.Cursor != nil {
+		value, err := cursorValue(filter.Cursor)
+		if err != nil {
+			return nil, apperrors.BadRequest(err)
+		}
+
+		builder = builder.Where(fmt.Sprintf("(%s, id) %s (?, ?)", sortExpr, comparator), value, filter.Cursor.ID)
+	}
+
+	builder = builder.OrderBy(sortExpr+" "+direction, "id "+direction)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		var user models.User
+		err = rows.Scan(
+			&user.ID,
+			&user.Email,
+			&user.AvatarURL,
+			&user.FullName,
+			&user.Slug,
+			&user.Bio,
+			&user.LastLoginAt,
+			&user.Role,
+			&user.IsVerified,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+
+		users = append(users, &user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return users, nil
+}
+
+// UsersCursorFor builds the keyset position pointing right after the given user.
+func UsersCursorFor(user *models.User, sortBy models.UsersSortField, descending bool) *models.UsersCursor {
+	cursor := &models.UsersCursor{SortBy: sortBy, Descending: descending, ID: user.ID}
+
+	switch sortBy {
+	case models.UsersSortByFullName:
+		cursor.Value = user.FullName
+	case models.UsersSortByLastLoginAt:
+		lastLogin := time.Unix(0, 0).UTC()
+		if user.LastLoginAt != nil {
+			lastLogin = *user.LastLoginAt
+		}
+		cursor.Value = lastLogin.Format(time.RFC3339Nano)
+	default:
+		cursor.Value = user.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	return cursor
+}
+
+func sortExpression(sortBy models.UsersSortField) (string, error) {
+	switch sortBy {
+	case models.UsersSortByCreatedAt:
+		return "created_at", nil
+	case models.UsersSortByFullName:
+		return "full_name", nil
+	case models.UsersSortByLastLoginAt:
+		return lastLoginSortExpr, nil
+	default:
+		return "", fmt.Errorf("unsupported sort field: %q", sortBy)
+	}
+}
+
+func cursorValue(cursor *models.UsersCursor) (any, error) {
+	if cursor.SortBy == models.UsersSortByFullName {
+		return cursor.Value, nil
+	}
+
+	return time.Parse(time.RFC3339Nano, cursor.Value)
+}
@@ -0,0 +1,281 @@
+package store
+
+import (
+	"context"
+	"github.com/Brain-Wave-Ecosystem/go-common/pkg/dbx"
+	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"github.com/Masterminds/squirrel"
+	"time"
+)
+
+// GetMFA returns the user's MFA enrollment or nil when the user never started one.
+func (s *Store) GetMFA(ctx context.Context, userID int64) (*models.UserMFA, error) {
+	builder := dbx.StatementBuilder.
+		Select("user_id", "secret_encrypted", "last_used_step", "confirmed_at", "created_at").
+		From("users_mfa").
+		Where(squirrel.Eq{"user_id": userID})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	var mfa models.UserMFA
+	err = s.db.QueryRow(ctx, query, args...).Scan(
+		&mfa.UserID,
+		&mfa.SecretEncrypted,
+		&mfa.LastUsedStep,
+		&mfa.ConfirmedAt,
+		&mfa.CreatedAt,
+	)
+
+	switch {
+	case dbx.IsNoRows(err):
+		return nil, nil
+	case err != nil:
+		return nil, apperrors.Internal(err)
+	}
+
+	return &mfa, nil
+}
+
+// UpsertPendingMFA stores a new unconfirmed secret, replacing a previous unconfirmed one.
+// A confirmed enrollment is never overwritten.
+func (s *Store) UpsertPendingMFA(ctx context.Context, userID int64, secretEncrypted []byte) error {
+	builder := dbx.StatementBuilder.
+		Insert("users_mfa").
+		Columns("user_id", "secret_encrypted").
+		Values(userID, secretEncrypted).
+		Suffix(`ON CONFLICT (user_id) DO UPDATE
+			SET secret_encrypted = EXCLUDED.secret_encrypted, last_used_step = NULL, created_at = NOW()
+			WHERE users_mfa.confirmed_at IS NULL`)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	cmd, err := s.db.Exec(ctx, query, args...)
+
+	switch {
+	case err != nil:
+		return apperrors.Internal(err)
+	case cmd.RowsAffected() == 0:
+		return apperrors.AlreadyExists("mfa", "user_id", userID)
+	}
+
+	return nil
+}
+
+func (s *Store) ConfirmMFA(ctx context.Context, userID int64) error {
+	builder := dbx.StatementBuilder.
+		Update("users_mfa").
+		Set("confirmed_at", time.Now()).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Eq{"confirmed_at": nil})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	cmd, err := s.db.Exec(ctx, query, args...)
+
+	switch {
+	case err != nil:
+		return apperrors.Internal(err)
+	case cmd.RowsAffected() == 0:
+		return apperrors.NotFound("pending mfa", "user_id", userID)
+	}
+
+	return nil
+}
+
+// UseMFAStep records the time step of an accepted code. It reports false when the step, or a later
+// one, was already used, which rejects replays of an intercepted code.
+func (s *Store) UseMFAStep(ctx context.Context, userID, step int64) (bool, error) {
+	builder := dbx.StatementBuilder.
+		Update("users_mfa").
+		Set("last_used_step", step).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Or{
+			squirrel.Eq{"last_used_step": nil},
+			squirrel.Lt{"last_used_step": step},
+		})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return false, apperrors.Internal(err)
+	}
+
+	cmd, err := s.db.Exec(ctx, query, args...)
+	if err != nil {
+		return false, apperrors.Internal(err)
+	}
+
+	return cmd.RowsAffected() > 0, nil
+}
+
+func (s *Store) DeleteMFA(ctx context.Context, userID int64) error {
+	codesBuilder := dbx.StatementBuilder.
+		Delete("users_mfa_recovery_codes").
+		Where(squirrel.Eq{"user_id": userID})
+
+	codesQuery, codesArgs, err := codesBuilder.ToSql()
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	builder := dbx.StatementBuilder.
+		Delete("users_mfa").
+		Where(squirrel.Eq{"user_id": userID})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	return s.WithTx(ctx, func(tx *Store) error {
+		if _, err := tx.db.Exec(ctx, codesQuery, codesArgs...); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if _, err := tx.db.Exec(ctx, query, args...); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		return nil
+	})
+}
+
+// ReplaceRecoveryCodes drops every previous recovery code of the user and stores the new hashes.
+func (s *Store) ReplaceRecoveryCodes(ctx context.Context, userID int64, hashes []string) error {
+	deleteBuilder := dbx.StatementBuilder.
+		Delete("users_mfa_recovery_codes").
+		Where(squirrel.Eq{"user_id": userID})
+
+	deleteQuery, deleteArgs, err := deleteBuilder.ToSql()
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	insertBuilder := dbx.StatementBuilder.
+		Insert("users_mfa_recovery_codes").
+		Columns("user_id", "code_hash")
+
+	for _, hash := range hashes {
+		insertBuilder = insertBuilder.Values(userID, hash)
+	}
+
+	insertQuery, insertArgs, err := insertBuilder.ToSql()
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	return s.WithTx(ctx, func(tx *Store) error {
+		if _, err := tx.db.Exec(ctx, deleteQuery, deleteArgs...); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if _, err := tx.db.Exec(ctx, insertQuery, insertArgs...); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		return nil
+	})
+}
+
+// UseRecoveryCode burns a matching unused recovery code and reports whether one existed.
+func (s *Store) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	builder := dbx.StatementBuilder.
+		Update("users_mfa_recovery_codes").
+		Set("used_at", time.Now()).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Eq{"code_hash": codeHash}).
+		Where(squirrel.Eq{"used_at": nil})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return false, apperrors.Internal(err)
+	}
+
+	cmd, err := s.db.Exec(ctx, query, args...)
+	if err != nil {
+		return false, apperrors.Internal(err)
+	}
+
+	return cmd.RowsAffected() > 0, nil
+}
+
+func (s *Store) CreateMFAChallenge(ctx context.Context, userID int64, challengeHash string, expiresAt time.Time) error {
+	builder := dbx.StatementBuilder.
+		Insert("users_mfa_challenges").
+		Columns("user_id", "challenge_hash", "expires_at").
+		Values(userID, challengeHash, expiresAt)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	if _, err = s.db.Exec(ctx, query, args...); err != nil {
+		return apperrors.Internal(err)
+	}
+
+	return nil
+}
+
+// AttemptMFAChallenge counts an attempt against a live challenge and returns its user. Challenges that
+// ran out of attempts or expired are reported as not found.
+func (s *Store) AttemptMFAChallenge(ctx context.Context, challengeHash string, maxAttempts int) (int64, error) {
+	builder := dbx.StatementBuilder.
+		Update("users_mfa_challenges").
+		Set("attempts", squirrel.Expr("attempts + 1")).
+		Where(squirrel.Eq{"challenge_hash": challengeHash}).
+		Where(squirrel.Eq{"consumed_at": nil}).
+		Where(squirrel.Gt{"expires_at": time.Now()}).
+		Where(squirrel.Lt{"attempts": maxAttempts}).
+		Suffix("RETURNING user_id")
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return 0, apperrors.Internal(err)
+	}
+
+	var userID int64
+	err = s.db.QueryRow(ctx, query, args...).Scan(&userID)
+
+	switch {
+	case dbx.IsNoRows(err):
+		return 0, apperrors.NotFound("mfa challenge", "challenge_id", "provided")
+	case err != nil:
+		return 0, apperrors.Internal(err)
+	}
+
+	return userID, nil
+}
+
+func (s *Store) ConsumeMFAChallenge(ctx context.Context, challengeHash string) error {
+	builder := dbx.StatementBuilder.
+		Update("users_mfa_challenges").
+		Set("consumed_at", time.Now()).
+		Where(squirrel.Eq{"challenge_hash": challengeHash}).
+		Where(squirrel.Eq{"consumed_at": nil})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	cmd, err := s.db.Exec(ctx, query, args...)
+
+	switch {
+	case err != nil:
+		return apperrors.Internal(err)
+	case cmd.RowsAffected() == 0:
+		return apperrors.NotFound("mfa challenge", "challenge_id", "provided")
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"github.com/Brain-Wave-Ecosystem/go-common/pkg/dbx"
+	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/events"
+	"github.com/Masterminds/squirrel"
+	"slices"
+	"time"
+)
+
+// EnqueueEvent stores an event that is not tied to a users mutation, such as an account lockout.
+func (s *Store) EnqueueEvent(ctx context.Context, event *events.Event) error {
+	return insertOutboxEvent(ctx, s.db, event)
+}
+
+// ProcessOutbox claims up to limit due events, hands them to publish and records the outcome. The claim
+// commits before anything is published, so no row lock or transaction is held while the broker is slow:
+// it pushes next_attempt_at lease ahead, which hides the events from other relays, and a relay that dies
+// mid-batch only delays its events until the lease runs out.
+func (s *Store) ProcessOutbox(ctx context.Context, limit int, lease time.Duration, publish func(ctx context.Context, event *events.Event) error, backoff func(attempts int) time.Duration) (int, error) {
+	claims, err := s.claimOutboxEvents(ctx, limit, lease)
+	if err != nil {
+		return 0, apperrors.Internal(err)
+	}
+
+	processed := 0
+
+	for _, claim := range claims {
+		update := dbx.StatementBuilder.
+			Update("users_outbox").
+			Where(squirrel.Eq{"id": claim.event.ID})
+
+		if publishErr := publish(ctx, claim.event); publishErr != nil {
+			update = update.
+				Set("last_error", publishErr.Error()).
+				Set("next_attempt_at", time.Now().Add(backoff(claim.attempts)))
+		} else {
+			update = update.
+				Set("last_error", nil).
+				Set("published_at", time.Now())
+			processed++
+		}
+
+		query, args, err := update.ToSql()
+		if err != nil {
+			return processed, apperrors.Internal(err)
+		}
+
+		// The publish outcome is recorded even when the relay is stopping.
+		if _, err = s.db.Exec(context.WithoutCancel(ctx), query, args...); err != nil {
+			return processed, apperrors.Internal(err)
+		}
+	}
+
+	return processed, nil
+}
+
+// DeletePublishedOutboxEvents deletes up to limit events published before publishedBefore, oldest first,
+// and returns how many it deleted. Pending events are never deleted, however old.
+func (s *Store) DeletePublishedOutboxEvents(ctx context.Context, publishedBefore time.Time, limit int) (int64, error) {
+	// Nested in the DELETE below, which numbers the placeholders of both.
+	expired := squirrel.
+		Select("id").
+		From("users_outbox").
+		Where(squirrel.Lt{"published_at": publishedBefore}).
+		OrderBy("published_at").
+		Limit(uint64(limit))
+
+	builder := dbx.StatementBuilder.
+		Delete("users_outbox").
+		Where(squirrel.Expr("id IN (?)", expired))
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return 0, apperrors.Internal(err)
+	}
+
+	cmd, err := s.db.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, apperrors.Internal(err)
+	}
+
+	return cmd.RowsAffected(), nil
+}
+
+type outboxClaim struct {
+	event    *events.Event
+	attempts int
+}
+
+// claimOutboxEvents counts an attempt on up to limit due events and leases them in a single statement.
+// SKIP LOCKED lets several relays claim from the table at once without waiting on each other.
+func (s *Store) claimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]outboxClaim, error) {
+	now := time.Now()
+
+	// Nested in the UPDATE below, which numbers the placeholders of both.
+	due := squirrel.
+		Select("id").
+		From("users_outbox").
+		Where(squirrel.Eq{"published_at": nil}).
+		Where(squirrel.LtOrEq{"next_attempt_at": now}).
+		OrderBy("id").
+		Limit(uint64(limit)).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	builder := dbx.StatementBuilder.
+		Update("users_outbox").
+		Set("attempts", squirrel.Expr("attempts + 1")).
+		Set("next_attempt_at", now.Add(lease)).
+		Where(squirrel.Expr("id IN (?)", due)).
+		Suffix("RETURNING id, event_type, user_id, payload, occurred_at, attempts")
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claims []outboxClaim
+	for rows.Next() {
+		claim := outboxClaim{event: &events.Event{}}
+		if err = rows.Scan(&claim.event.ID, &claim.event.Type, &claim.event.UserID, &claim.event.Payload, &claim.event.OccurredAt, &claim.attempts); err != nil {
+			return nil, err
+		}
+
+		claims = append(claims, claim)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not keep the ORDER BY of the subquery.
+	slices.SortFunc(claims, func(a, b outboxClaim) int { return cmp.Compare(a.event.ID, b.event.ID) })
+
+	return claims, nil
+}
+
+func insertOutboxEvent(ctx context.Context, db querier, event *events.Event) error {
+	builder := dbx.StatementBuilder.
+		Insert("users_outbox").
+		Columns("event_type", "user_id", "payload", "occurred_at").
+		Values(event.Type, event.UserID, event.Payload, event.OccurredAt)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx, query, args...)
+	return err
+}
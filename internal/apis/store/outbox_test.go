@@ -0,0 +1,199 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/events"
+	"slices"
+	"testing"
+	"time"
+)
+
+func enqueueTestEvents(t *testing.T, s *Store, userIDs ...int64) {
+	t.Helper()
+
+	for _, userID := range userIDs {
+		event, err := events.UserDeleted(userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = s.EnqueueEvent(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestProcessOutbox(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	enqueueTestEvents(t, s, 1, 2, 3)
+
+	var published []int64
+	publish := func(_ context.Context, event *events.Event) error {
+		if event.UserID == 2 {
+			return errors.New("broker unavailable")
+		}
+
+		published = append(published, event.UserID)
+		return nil
+	}
+
+	backoff := func(attempts int) time.Duration {
+		if attempts != 1 {
+			t.Errorf("backoff(%d), want the first attempt counted", attempts)
+		}
+		return time.Hour
+	}
+
+	processed, err := s.ProcessOutbox(ctx, 10, time.Minute, publish, backoff)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if processed != 2 || !slices.Equal(published, []int64{1, 3}) {
+		t.Errorf("ProcessOutbox = %d, published %v, want 2 and [1 3] in order", processed, published)
+	}
+
+	rows, err := s.db.Query(ctx, "SELECT user_id, attempts, published_at IS NOT NULL, COALESCE(last_error, ''), next_attempt_at > NOW() + interval '30 minutes' FROM users_outbox ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	type outboxRow struct {
+		userID    int64
+		attempts  int
+		published bool
+		lastError string
+		backedOff bool
+	}
+
+	want := []outboxRow{
+		{userID: 1, attempts: 1, published: true},
+		{userID: 2, attempts: 1, lastError: "broker unavailable", backedOff: true},
+		{userID: 3, attempts: 1, published: true},
+	}
+
+	var got []outboxRow
+	for rows.Next() {
+		var row outboxRow
+		if err = rows.Scan(&row.userID, &row.attempts, &row.published, &row.lastError, &row.backedOff); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, row)
+	}
+
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("outbox = %+v, want %+v", got, want)
+	}
+
+	published = nil
+
+	if processed, err = s.ProcessOutbox(ctx, 10, time.Minute, publish, backoff); err != nil || processed != 0 || published != nil {
+		t.Errorf("second ProcessOutbox = %d, %v, published %v, want nothing due", processed, err, published)
+	}
+}
+
+func TestClaimOutboxEventsLeases(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	enqueueTestEvents(t, s, 1, 2, 3)
+
+	claimedBy := func(claims []outboxClaim) []int64 {
+		var ids []int64
+		for _, claim := range claims {
+			ids = append(ids, claim.event.UserID)
+		}
+		return ids
+	}
+
+	first, err := s.claimOutboxEvents(ctx, 2, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := s.claimOutboxEvents(ctx, 2, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := claimedBy(first); !slices.Equal(got, []int64{1, 2}) {
+		t.Errorf("first claim = %v, want [1 2]", got)
+	}
+
+	if got := claimedBy(second); !slices.Equal(got, []int64{3}) {
+		t.Errorf("second claim = %v, want [3]: leased events must not be claimed again", got)
+	}
+
+	// An expired lease, as left behind by a relay that died mid-batch, makes the events due again.
+	if _, err = s.db.Exec(ctx, "UPDATE users_outbox SET next_attempt_at = NOW() - interval '1 second' WHERE user_id = 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	third, err := s.claimOutboxEvents(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(third) != 1 || third[0].event.UserID != 1 || third[0].attempts != 2 {
+		t.Errorf("claim after the lease expired = %+v, want event of user 1 on its second attempt", third)
+	}
+}
+
+func TestDeletePublishedOutboxEvents(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	enqueueTestEvents(t, s, 1, 2, 3, 4)
+
+	// 1 and 2 were published long ago, 3 just now, and 4 is still pending however old it is.
+	if _, err := s.db.Exec(ctx, "UPDATE users_outbox SET published_at = NOW() - interval '30 days' WHERE user_id IN (1, 2)"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.db.Exec(ctx, "UPDATE users_outbox SET published_at = NOW() WHERE user_id = 3"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.db.Exec(ctx, "UPDATE users_outbox SET occurred_at = NOW() - interval '30 days' WHERE user_id = 4"); err != nil {
+		t.Fatal(err)
+	}
+
+	publishedBefore := time.Now().Add(-7 * 24 * time.Hour)
+
+	deleted, err := s.DeletePublishedOutboxEvents(ctx, publishedBefore, 1)
+	if err != nil || deleted != 1 {
+		t.Fatalf("DeletePublishedOutboxEvents = %d, %v, want 1 deleted within the limit", deleted, err)
+	}
+
+	if deleted, err = s.DeletePublishedOutboxEvents(ctx, publishedBefore, 10); err != nil || deleted != 1 {
+		t.Fatalf("second DeletePublishedOutboxEvents = %d, %v, want the other expired event", deleted, err)
+	}
+
+	rows, err := s.db.Query(ctx, "SELECT user_id FROM users_outbox ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var remaining []int64
+	for rows.Next() {
+		var userID int64
+		if err = rows.Scan(&userID); err != nil {
+			t.Fatal(err)
+		}
+		remaining = append(remaining, userID)
+	}
+
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(remaining, []int64{3, 4}) {
+		t.Errorf("remaining events of users %v, want [3 4]", remaining)
+	}
+}
@@ -33,11 +33,17 @@ func (s *Store) AddPasswordHistory(ctx context.Context, userID int64, passwordHa
 	return nil
 }
 
-func (s *Store) GetPasswordHistory(ctx context.Context, userID int64) ([]*models.UserPasswordHistory, error) {
+// GetPasswordHistory returns the user's latest limit password hashes, newest first; limit 0 returns all of them.
+func (s *Store) GetPasswordHistory(ctx context.Context, userID int64, limit int) ([]*models.UserPasswordHistory, error) {
 	builder := dbx.StatementBuilder.
 		Select("pass_hash", "created_at").
 		From("users_password_history").
-		Where(squirrel.Eq{"user_id": userID})
+		Where(squirrel.Eq{"user_id": userID}).
+		OrderBy("created_at DESC", "id DESC")
+
+	if limit > 0 {
+		builder = builder.Limit(uint64(limit))
+	}
 
 	query, args, err := builder.ToSql()
 	if err != nil {
@@ -58,3 +64,49 @@ func (s *Store) GetPasswordHistory(ctx context.Context, userID int64) ([]*models
 
 	return histories, nil
 }
+
+func (s *Store) GetPasswordHash(ctx context.Context, userID int64) (string, error) {
+	builder := dbx.StatementBuilder.
+		Select("pass_hash").
+		From("users").
+		Where(squirrel.Eq{"id": userID}).
+		Where(squirrel.Eq{"deleted_at": nil})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return "", apperrors.Internal(err)
+	}
+
+	var hash string
+	err = s.db.QueryRow(ctx, query, args...).Scan(&hash)
+
+	switch {
+	case dbx.IsNoRows(err):
+		return "", apperrors.NotFound("user", "id", userID)
+	case err != nil:
+		return "", apperrors.Internal(err)
+	}
+
+	return hash, nil
+}
+
+// ReplacePasswordHash swaps the stored hash for an equivalent one of the same password, e.g. after an
+// algorithm upgrade. It is not a password change: no event is enqueued and a concurrent change wins.
+func (s *Store) ReplacePasswordHash(ctx context.Context, userID int64, oldHash, newHash string) error {
+	builder := dbx.StatementBuilder.
+		Update("users").
+		Set("pass_hash", newHash).
+		Where(squirrel.Eq{"id": userID}).
+		Where(squirrel.Eq{"pass_hash": oldHash})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	if _, err = s.db.Exec(ctx, query, args...); err != nil {
+		return apperrors.Internal(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"github.com/Brain-Wave-Ecosystem/go-common/pkg/dbx"
+	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/events"
+	"github.com/Masterminds/squirrel"
+	"time"
+)
+
+// userDataTables hold rows that only make sense for a live account, or tie the user to client IPs like the
+// access denial audit, and are dropped on purge.
+var userDataTables = []string{
+	"users_refresh_tokens",
+	"users_sessions",
+	"users_password_history",
+	"users_verification_tokens",
+	"users_password_reset_tokens",
+	"users_mfa_challenges",
+	"users_mfa_recovery_codes",
+	"users_mfa",
+	"users_access_denials",
+}
+
+// RestoreUser undoes a soft deletion made after deletedAfter, as long as the account was not purged.
+func (s *Store) RestoreUser(ctx context.Context, userID int64, deletedAfter time.Time) error {
+	builder := dbx.StatementBuilder.
+		Update("users").
+		Set("deleted_at", nil).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": userID}).
+		Where(squirrel.Gt{"deleted_at": deletedAfter}).
+		Where(squirrel.Eq{"purged_at": nil})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	return s.WithTx(ctx, func(tx *Store) error {
+		cmd, err := tx.db.Exec(ctx, query, args...)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if cmd.RowsAffected() == 0 {
+			return apperrors.NotFound("restorable user", "id", userID)
+		}
+
+		event, err := events.UserRestored(userID)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if err = insertOutboxEvent(ctx, tx.db, event); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		return nil
+	})
+}
+
+// PurgeCandidates returns up to limit users soft-deleted before deletedBefore and not yet purged, oldest first.
+func (s *Store) PurgeCandidates(ctx context.Context, deletedBefore time.Time, limit int) ([]int64, error) {
+	builder := dbx.StatementBuilder.
+		Select("id").
+		From("users").
+		Where(squirrel.LtOrEq{"deleted_at": deletedBefore}).
+		Where(squirrel.Eq{"purged_at": nil}).
+		OrderBy("deleted_at", "id").
+		Limit(uint64(limit))
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, apperrors.Internal(err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return ids, nil
+}
+
+// PurgeUser permanently removes a soft-deleted user's data. With anonymize the users row is kept,
+// stripped of PII, so the id stays valid for other services; otherwise the row itself is deleted.
+// It reports false when the user no longer qualifies, e.g. was restored since PurgeCandidates.
+func (s *Store) PurgeUser(ctx context.Context, userID int64, deletedBefore time.Time, anonymize bool) (bool, error) {
+	purged := false
+
+	err := s.WithTx(ctx, func(tx *Store) error {
+		purged = false
+
+		builder := dbx.StatementBuilder.
+			Select("id").
+			From("users").
+			Where(squirrel.Eq{"id": userID}).
+			Where(squirrel.LtOrEq{"deleted_at": deletedBefore}).
+			Where(squirrel.Eq{"purged_at": nil}).
+			Suffix("FOR UPDATE")
+
+		query, args, err := builder.ToSql()
+		if err != nil {
+			return err
+		}
+
+		var id int64
+
+		err = tx.db.QueryRow(ctx, query, args...).Scan(&id)
+
+		switch {
+		case dbx.IsNoRows(err):
+			return nil
+		case err != nil:
+			return err
+		}
+
+		if err = tx.deleteUserData(ctx, userID); err != nil {
+			return err
+		}
+
+		if anonymize {
+			err = tx.anonymizeUser(ctx, userID)
+		} else {
+			err = tx.deleteUserRow(ctx, userID)
+		}
+		if err != nil {
+			return err
+		}
+
+		event, err := events.UserPurged(userID, anonymize)
+		if err != nil {
+			return err
+		}
+
+		if err = insertOutboxEvent(ctx, tx.db, event); err != nil {
+			return err
+		}
+
+		purged = true
+		return nil
+	})
+	if err != nil {
+		return false, apperrors.Internal(err)
+	}
+
+	return purged, nil
+}
+
+func (s *Store) deleteUserData(ctx context.Context, userID int64) error {
+	for _, table := range userDataTables {
+		query, args, err := dbx.StatementBuilder.Delete(table).Where(squirrel.Eq{"user_id": userID}).ToSql()
+		if err != nil {
+			return err
+		}
+
+		if _, err = s.db.Exec(ctx, query, args...); err != nil {
+			return fmt.Errorf("purge %s: %w", table, err)
+		}
+	}
+
+	return nil
+}
+
+// anonymizeUser replaces every PII column with a tombstone derived from the id, which keeps the
+// unique constraints on email and slug satisfied. An account that was still active is marked deleted.
+func (s *Store) anonymizeUser(ctx context.Context, userID int64) error {
+	now := time.Now()
+	builder := dbx.StatementBuilder.
+		Update("users").
+		Set("email", fmt.Sprintf("deleted-%d@invalid", userID)).
+		Set("full_name", "Deleted user").
+		Set("slug", fmt.Sprintf("deleted-%d", userID)).
+		Set("avatar_url", nil).
+		Set("bio", nil).
+		Set("pass_hash", "").
+		Set("last_login_at", nil).
+		Set("suspension_reason", nil).
+		Set("updated_at", now).
+		Set("deleted_at", squirrel.Expr("COALESCE(deleted_at, ?)", now)).
+		Set("purged_at", now).
+		Where(squirrel.Eq{"id": userID})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(ctx, query, args...)
+	return err
+}
+
+// deleteUserRow drops the users row after detaching the audit references other rows hold to it. The
+// user's own erasure receipt cannot outlive the row it points to, so it goes with it.
+func (s *Store) deleteUserRow(ctx context.Context, userID int64) error {
+	builders := []squirrel.Sqlizer{
+		dbx.StatementBuilder.Update("users").Set("suspended_by", nil).Where(squirrel.Eq{"suspended_by": userID}),
+		dbx.StatementBuilder.Update("users_role_history").Set("changed_by", nil).Where(squirrel.Eq{"changed_by": userID}),
+		dbx.StatementBuilder.Update("users_erasures").Set("requested_by", nil).Where(squirrel.Eq{"requested_by": userID}),
+		dbx.StatementBuilder.Delete("users_role_history").Where(squirrel.Eq{"user_id": userID}),
+		dbx.StatementBuilder.Delete("users_erasures").Where(squirrel.Eq{"user_id": userID}),
+		dbx.StatementBuilder.Delete("users").Where(squirrel.Eq{"id": userID}),
+	}
+
+	for _, builder := range builders {
+		query, args, err := builder.ToSql()
+		if err != nil {
+			return err
+		}
+
+		if _, err = s.db.Exec(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
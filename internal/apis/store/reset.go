@@ -0,0 +1,124 @@
+package store
+
+import (
+	"context"
+	"github.com/Brain-Wave-Ecosystem/go-common/pkg/dbx"
+	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Masterminds/squirrel"
+	"time"
+)
+
+func (s *Store) CreatePasswordResetToken(ctx context.Context, userID int64, tokenHash string, expiresAt time.Time) error {
+	builder := dbx.StatementBuilder.
+		Insert("users_password_reset_tokens").
+		Columns("user_id", "token_hash", "expires_at").
+		Values(userID, tokenHash, expiresAt)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	if _, err = s.db.Exec(ctx, query, args...); err != nil {
+		return apperrors.Internal(err)
+	}
+
+	return nil
+}
+
+func (s *Store) LatestPasswordResetTokenAt(ctx context.Context, userID int64) (*time.Time, error) {
+	builder := dbx.StatementBuilder.
+		Select("MAX(created_at)").
+		From("users_password_reset_tokens").
+		Where(squirrel.Eq{"user_id": userID})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	var latest *time.Time
+	if err = s.db.QueryRow(ctx, query, args...).Scan(&latest); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return latest, nil
+}
+
+// GetPasswordResetTokenOwner returns the user an unexpired, unused reset token was issued to.
+func (s *Store) GetPasswordResetTokenOwner(ctx context.Context, tokenHash string) (int64, error) {
+	builder := dbx.StatementBuilder.
+		Select("user_id").
+		From("users_password_reset_tokens").
+		Where(squirrel.Eq{"token_hash": tokenHash}).
+		Where(squirrel.Eq{"consumed_at": nil}).
+		Where(squirrel.Gt{"expires_at": time.Now()})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return 0, apperrors.Internal(err)
+	}
+
+	var userID int64
+	err = s.db.QueryRow(ctx, query, args...).Scan(&userID)
+
+	switch {
+	case dbx.IsNoRows(err):
+		return 0, apperrors.NotFound("password reset token", "token", "provided")
+	case err != nil:
+		return 0, apperrors.Internal(err)
+	}
+
+	return userID, nil
+}
+
+// ConsumePasswordResetToken marks an unexpired, unused reset token as consumed and returns its owner.
+// Like ConsumeVerificationToken, the single UPDATE lets concurrent resets with the same token succeed at most once.
+func (s *Store) ConsumePasswordResetToken(ctx context.Context, tokenHash string) (int64, error) {
+	now := time.Now()
+
+	builder := dbx.StatementBuilder.
+		Update("users_password_reset_tokens").
+		Set("consumed_at", now).
+		Where(squirrel.Eq{"token_hash": tokenHash}).
+		Where(squirrel.Eq{"consumed_at": nil}).
+		Where(squirrel.Gt{"expires_at": now}).
+		Suffix("RETURNING user_id")
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return 0, apperrors.Internal(err)
+	}
+
+	var userID int64
+	err = s.db.QueryRow(ctx, query, args...).Scan(&userID)
+
+	switch {
+	case dbx.IsNoRows(err):
+		return 0, apperrors.NotFound("password reset token", "token", "provided")
+	case err != nil:
+		return 0, apperrors.Internal(err)
+	}
+
+	return userID, nil
+}
+
+// InvalidatePasswordResetTokens consumes every outstanding reset token of the user.
+func (s *Store) InvalidatePasswordResetTokens(ctx context.Context, userID int64) error {
+	builder := dbx.StatementBuilder.
+		Update("users_password_reset_tokens").
+		Set("consumed_at", time.Now()).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Eq{"consumed_at": nil})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	if _, err = s.db.Exec(ctx, query, args...); err != nil {
+		return apperrors.Internal(err)
+	}
+
+	return nil
+}
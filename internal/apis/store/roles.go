@@ -0,0 +1,188 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"github.com/Brain-Wave-Ecosystem/go-common/pkg/dbx"
+	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/events"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/rbac"
+	"github.com/Masterminds/squirrel"
+	"time"
+)
+
+const (
+	adminRole = "admin"
+	guestRole = "guest"
+	// verifiedRole is what a guest becomes once the email is confirmed.
+	verifiedRole = "user"
+)
+
+// ErrLastAdmin is returned when a role change would leave no active admin.
+var ErrLastAdmin = errors.New("cannot remove the last admin")
+
+// ChangeUserRole sets the user's role, records the change in users_role_history and enqueues the event,
+// all in one transaction. change.OldRole is filled in; setting the current role again is a no-op.
+// Demoting an admin locks every admin row first, so two concurrent demotions cannot both pass the
+// last-admin check.
+func (s *Store) ChangeUserRole(ctx context.Context, change *models.RoleChange) error {
+	return s.WithTx(ctx, func(tx *Store) error {
+		oldRole, err := tx.lockUserRole(ctx, change.UserID)
+		if err != nil {
+			return err
+		}
+
+		change.OldRole = oldRole
+
+		if oldRole == change.NewRole {
+			return nil
+		}
+
+		if oldRole == adminRole {
+			if err = tx.ensureAnotherAdmin(ctx); err != nil {
+				return err
+			}
+		}
+
+		updateBuilder := dbx.StatementBuilder.
+			Update("users").
+			Set("role", change.NewRole).
+			Set("updated_at", time.Now()).
+			Where(squirrel.Eq{"id": change.UserID})
+
+		updateQuery, updateArgs, err := updateBuilder.ToSql()
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if _, err = tx.db.Exec(ctx, updateQuery, updateArgs...); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		var changedBy *int64
+		if change.ChangedBy != 0 {
+			changedBy = &change.ChangedBy
+		}
+
+		historyBuilder := dbx.StatementBuilder.
+			Insert("users_role_history").
+			Columns("user_id", "old_role", "new_role", "changed_by", "reason").
+			Values(change.UserID, oldRole, change.NewRole, changedBy, change.Reason)
+
+		historyQuery, historyArgs, err := historyBuilder.ToSql()
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if _, err = tx.db.Exec(ctx, historyQuery, historyArgs...); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		event, err := events.UserRoleChanged(change)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if err = insertOutboxEvent(ctx, tx.db, event); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		return nil
+	})
+}
+
+// LockManagedUser locks the user's row, deleted or not, and returns ErrTargetNotOutranked unless actorRole
+// may manage the user's role. Call it in the transaction of the change it guards, so a concurrent promotion
+// cannot slip past it.
+func (s *Store) LockManagedUser(ctx context.Context, userID int64, actorRole rbac.Role) error {
+	builder := dbx.StatementBuilder.
+		Select("role").
+		From("users").
+		Where(squirrel.Eq{"id": userID}).
+		Suffix("FOR UPDATE")
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	var role string
+
+	err = s.db.QueryRow(ctx, query, args...).Scan(&role)
+
+	switch {
+	case dbx.IsNoRows(err):
+		return apperrors.NotFound("user", "id", userID)
+	case err != nil:
+		return apperrors.Internal(err)
+	}
+
+	if !actorRole.CanManage(rbac.Role(role)) {
+		return ErrTargetNotOutranked
+	}
+
+	return nil
+}
+
+func (s *Store) lockUserRole(ctx context.Context, userID int64) (string, error) {
+	builder := dbx.StatementBuilder.
+		Select("role").
+		From("users").
+		Where(squirrel.Eq{"id": userID}).
+		Where(squirrel.Eq{"deleted_at": nil}).
+		Suffix("FOR UPDATE")
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return "", apperrors.Internal(err)
+	}
+
+	var role string
+
+	err = s.db.QueryRow(ctx, query, args...).Scan(&role)
+
+	switch {
+	case dbx.IsNoRows(err):
+		return "", apperrors.NotFound("user", "id", userID)
+	case err != nil:
+		return "", apperrors.Internal(err)
+	}
+
+	return role, nil
+}
+
+func (s *Store) ensureAnotherAdmin(ctx context.Context) error {
+	builder := dbx.StatementBuilder.
+		Select("id").
+		From("users").
+		Where(squirrel.Eq{"role": adminRole}).
+		Where(squirrel.Eq{"deleted_at": nil}).
+		Suffix("FOR UPDATE")
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	defer rows.Close()
+
+	admins := 0
+	for rows.Next() {
+		admins++
+	}
+
+	if err = rows.Err(); err != nil {
+		return apperrors.Internal(err)
+	}
+
+	if admins <= 1 {
+		return ErrLastAdmin
+	}
+
+	return nil
+}
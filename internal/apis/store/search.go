@@ -0,0 +1,113 @@
+package store
+
+import (
+	"context"
+	"github.com/Brain-Wave-Ecosystem/go-common/pkg/dbx"
+	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"github.com/Masterminds/squirrel"
+	"strings"
+	"unicode"
+)
+
+// SearchUsers ranks public, non-deleted users by full-text match on name and slug, trigram similarity
+// for typos, and, with matchEmail, email prefix. Each hit reports which field matched best. Without
+// matchEmail the email is neither matched nor returned, so a search cannot be used to probe addresses.
+func (s *Store) SearchUsers(ctx context.Context, query string, limit, offset int, matchEmail bool) ([]*models.UserSearchHit, error) {
+	tsQuery := prefixTSQuery(query)
+
+	emailColumn := "'' AS email"
+	emailMatch := squirrel.Sqlizer(squirrel.Expr("FALSE"))
+
+	if matchEmail {
+		emailColumn = "email"
+		emailMatch = squirrel.Expr("lower(email) LIKE ?", escapeLike(strings.ToLower(query))+"%")
+	}
+
+	builder := dbx.StatementBuilder.
+		Select("id", emailColumn, "avatar_url", "full_name", "slug", "bio", "last_login_at", "role", "is_verified", "created_at", "updated_at").
+		Column(squirrel.Expr(`CASE
+			WHEN ? THEN 'email'
+			WHEN search_vector @@ to_tsquery('simple', ?) OR similarity(full_name, ?) >= similarity(slug, ?) THEN 'full_name'
+			ELSE 'slug' END AS matched_field`, emailMatch, tsQuery, query, query)).
+		Column(squirrel.Expr(`GREATEST(
+			ts_rank(search_vector, to_tsquery('simple', ?)),
+			similarity(full_name, ?),
+			similarity(slug, ?),
+			CASE WHEN ? THEN 1 ELSE 0 END
+		) AS rank`, tsQuery, query, query, emailMatch)).
+		From("users").
+		Where(squirrel.Eq{"deleted_at": nil}).
+		Where(squirrel.Eq{"profile_visibility": "public"}).
+		Where(squirrel.Or{
+			squirrel.Expr("search_vector @@ to_tsquery('simple', ?)", tsQuery),
+			squirrel.Expr("full_name % ?", query),
+			squirrel.Expr("slug % ?", query),
+			emailMatch,
+		}).
+		OrderBy("rank DESC", "id ASC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset))
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	rows, err := s.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	defer rows.Close()
+
+	var hits []*models.UserSearchHit
+	for rows.Next() {
+		user := &models.User{}
+		hit := &models.UserSearchHit{User: user}
+
+		err = rows.Scan(
+			&user.ID,
+			&user.Email,
+			&user.AvatarURL,
+			&user.FullName,
+			&user.Slug,
+			&user.Bio,
+			&user.LastLoginAt,
+			&user.Role,
+			&user.IsVerified,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&hit.MatchedField,
+			&hit.Rank,
+		)
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+
+		hits = append(hits, hit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return hits, nil
+}
+
+// prefixTSQuery turns free text into a tsquery matching every word as a prefix, e.g. "ann sm" -> "ann:* & sm:*".
+func prefixTSQuery(query string) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+
+	return strings.Join(words, " & ")
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
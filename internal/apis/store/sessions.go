@@ -0,0 +1,212 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"github.com/Brain-Wave-Ecosystem/go-common/pkg/dbx"
+	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidRefreshToken covers unknown tokens and tokens of expired or revoked sessions.
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused is returned for a token that was already rotated; its session has been revoked.
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+)
+
+var sessionColumns = []string{"id", "user_id", "user_agent", "ip", "created_at", "last_used_at", "expires_at"}
+
+// CreateSession stores the session with its first refresh token and fills in ID and the timestamps.
+func (s *Store) CreateSession(ctx context.Context, session *models.Session, tokenHash string) error {
+	builder := dbx.StatementBuilder.
+		Insert("users_sessions").
+		Columns("user_id", "user_agent", "ip", "expires_at").
+		Values(session.UserID, session.UserAgent, session.IP, session.ExpiresAt).
+		Suffix("RETURNING id, created_at, last_used_at")
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	return s.WithTx(ctx, func(tx *Store) error {
+		if err := tx.db.QueryRow(ctx, query, args...).Scan(&session.ID, &session.CreatedAt, &session.LastUsedAt); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		return tx.insertRefreshToken(ctx, session.ID, session.UserID, tokenHash)
+	})
+}
+
+// RotateRefreshToken exchanges a refresh token for newTokenHash and returns the refreshed session.
+// Every token can be used once: presenting one that was already rotated means it leaked, so the whole
+// session, the token family, is revoked and ErrRefreshTokenReused returned.
+func (s *Store) RotateRefreshToken(ctx context.Context, tokenHash, newTokenHash string, expiresAt time.Time, userAgent, ip string) (*models.Session, error) {
+	var session *models.Session
+	reused := false
+
+	err := s.WithTx(ctx, func(tx *Store) error {
+		session, reused = nil, false
+
+		lookup := dbx.StatementBuilder.
+			Select("t.id", "t.session_id", "t.user_id", "t.rotated_at IS NOT NULL").
+			From("users_refresh_tokens t").
+			Join("users_sessions s ON s.id = t.session_id").
+			Join("users u ON u.id = t.user_id").
+			Where(squirrel.Eq{"t.token_hash": tokenHash}).
+			Where(squirrel.Eq{"s.revoked_at": nil}).
+			Where(squirrel.Expr("s.expires_at > NOW()")).
+			Where(squirrel.Eq{"u.deleted_at": nil}).
+			Suffix("FOR UPDATE OF t, s")
+
+		query, args, err := lookup.ToSql()
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		var tokenID, sessionID, userID int64
+		var rotated bool
+
+		err = tx.db.QueryRow(ctx, query, args...).Scan(&tokenID, &sessionID, &userID, &rotated)
+
+		switch {
+		case dbx.IsNoRows(err):
+			return ErrInvalidRefreshToken
+		case err != nil:
+			return apperrors.Internal(err)
+		}
+
+		if rotated {
+			reused = true
+			_, err = tx.revokeSessions(ctx, squirrel.Eq{"id": sessionID}, models.SessionRevokedTokenReuse)
+			return err
+		}
+
+		now := time.Now()
+
+		rotate := dbx.StatementBuilder.
+			Update("users_refresh_tokens").
+			Set("rotated_at", now).
+			Where(squirrel.Eq{"id": tokenID})
+
+		if query, args, err = rotate.ToSql(); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if _, err = tx.db.Exec(ctx, query, args...); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if err = tx.insertRefreshToken(ctx, sessionID, userID, newTokenHash); err != nil {
+			return err
+		}
+
+		touch := dbx.StatementBuilder.
+			Update("users_sessions").
+			Set("user_agent", userAgent).
+			Set("ip", ip).
+			Set("last_used_at", now).
+			Set("expires_at", expiresAt).
+			Where(squirrel.Eq{"id": sessionID}).
+			Suffix("RETURNING " + strings.Join(sessionColumns, ", "))
+
+		if query, args, err = touch.ToSql(); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		rows, err := tx.db.Query(ctx, query, args...)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if session, err = pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByPos[models.Session]); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if reused {
+		return nil, ErrRefreshTokenReused
+	}
+
+	return session, nil
+}
+
+// ListSessions returns the user's active sessions, most recently used first.
+func (s *Store) ListSessions(ctx context.Context, userID int64) ([]*models.Session, error) {
+	builder := dbx.StatementBuilder.
+		Select(sessionColumns...).
+		From("users_sessions").
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Eq{"revoked_at": nil}).
+		Where(squirrel.Expr("expires_at > NOW()")).
+		OrderBy("last_used_at DESC")
+
+	return collect(ctx, s, builder, pgx.RowToAddrOfStructByPos[models.Session])
+}
+
+func (s *Store) RevokeSession(ctx context.Context, userID, sessionID int64, reason string) error {
+	revoked, err := s.revokeSessions(ctx, squirrel.Eq{"id": sessionID, "user_id": userID}, reason)
+	if err != nil {
+		return err
+	}
+
+	if revoked == 0 {
+		return apperrors.NotFound("session", "id", sessionID)
+	}
+
+	return nil
+}
+
+// RevokeAllSessions revokes every active session of the user and returns how many there were.
+func (s *Store) RevokeAllSessions(ctx context.Context, userID int64, reason string) (int64, error) {
+	return s.revokeSessions(ctx, squirrel.Eq{"user_id": userID}, reason)
+}
+
+func (s *Store) revokeSessions(ctx context.Context, where squirrel.Sqlizer, reason string) (int64, error) {
+	builder := dbx.StatementBuilder.
+		Update("users_sessions").
+		Set("revoked_at", time.Now()).
+		Set("revoke_reason", reason).
+		Where(where).
+		Where(squirrel.Eq{"revoked_at": nil})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return 0, apperrors.Internal(err)
+	}
+
+	cmd, err := s.db.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, apperrors.Internal(err)
+	}
+
+	return cmd.RowsAffected(), nil
+}
+
+func (s *Store) insertRefreshToken(ctx context.Context, sessionID, userID int64, tokenHash string) error {
+	builder := dbx.StatementBuilder.
+		Insert("users_refresh_tokens").
+		Columns("session_id", "user_id", "token_hash").
+		Values(sessionID, userID, tokenHash)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	if _, err = s.db.Exec(ctx, query, args...); err != nil {
+		return apperrors.Internal(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/tokens"
+	"testing"
+	"time"
+)
+
+func TestRotateRefreshToken(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	user := createTestUser(t, s, "Rotation")
+
+	first, second, third := tokens.Hash("first"), tokens.Hash("second"), tokens.Hash("third")
+
+	session := &models.Session{UserID: user.ID, UserAgent: "test", IP: "198.51.100.1", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := s.CreateSession(ctx, session, first); err != nil {
+		t.Fatal(err)
+	}
+
+	expiresAt := time.Now().Add(2 * time.Hour)
+
+	steps := []struct {
+		name        string
+		token       string
+		next        string
+		wantErr     error
+		wantRevoked bool
+	}{
+		{name: "rotate", token: first, next: second},
+		{name: "unknown token", token: tokens.Hash("unknown"), next: third, wantErr: ErrInvalidRefreshToken},
+		{name: "reuse revokes the family", token: first, next: third, wantErr: ErrRefreshTokenReused, wantRevoked: true},
+		{name: "current token of a revoked family", token: second, next: third, wantErr: ErrInvalidRefreshToken, wantRevoked: true},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			refreshed, err := s.RotateRefreshToken(ctx, step.token, step.next, expiresAt, "rotated", "198.51.100.2")
+
+			if !errors.Is(err, step.wantErr) {
+				t.Fatalf("RotateRefreshToken = %v, want %v", err, step.wantErr)
+			}
+
+			if err == nil {
+				if refreshed.ID != session.ID || refreshed.UserAgent != "rotated" || refreshed.IP != "198.51.100.2" {
+					t.Errorf("RotateRefreshToken = %+v, want session %d refreshed", refreshed, session.ID)
+				}
+			}
+
+			active, err := s.ListSessions(ctx, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if revoked := len(active) == 0; revoked != step.wantRevoked {
+				t.Errorf("session revoked = %v, want %v", revoked, step.wantRevoked)
+			}
+		})
+	}
+
+	var reason string
+	if err := s.db.QueryRow(ctx, "SELECT revoke_reason FROM users_sessions WHERE id = $1", session.ID).Scan(&reason); err != nil {
+		t.Fatal(err)
+	}
+
+	if reason != models.SessionRevokedTokenReuse {
+		t.Errorf("revoke_reason = %q, want %q", reason, models.SessionRevokedTokenReuse)
+	}
+}
+
+func TestRotateRefreshTokenOfExpiredSession(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	user := createTestUser(t, s, "Expired")
+
+	session := &models.Session{UserID: user.ID, ExpiresAt: time.Now().Add(-time.Minute)}
+	if err := s.CreateSession(ctx, session, tokens.Hash("expired")); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := s.RotateRefreshToken(ctx, tokens.Hash("expired"), tokens.Hash("next"), time.Now().Add(time.Hour), "", "")
+	if !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("RotateRefreshToken = %v, want ErrInvalidRefreshToken", err)
+	}
+}
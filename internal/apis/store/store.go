@@ -2,8 +2,10 @@ package store
 
 import (
 	"context"
+	"errors"
 	"github.com/Brain-Wave-Ecosystem/go-common/pkg/dbx"
 	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/events"
 	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
 	"github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -13,17 +15,22 @@ import (
 	"time"
 )
 
+// errNoRowsAffected aborts a mutation transaction when the target row does not exist.
+var errNoRowsAffected = errors.New("no rows affected")
+
 type Store struct {
-	db *pgxpool.Pool
+	pool *pgxpool.Pool
+	db   querier
+	tx   bool
 }
 
 func NewStore(db *pgxpool.Pool) *Store {
-	return &Store{db: db}
+	return &Store{pool: db, db: db}
 }
 
 func (s *Store) GetUserByID(ctx context.Context, userID int) (*models.User, error) {
 	builder := dbx.StatementBuilder.
-		Select("id", "email", "avatar_url", "full_name", "slug", "bio", "last_login_at", "role", "created_at", "updated_at").
+		Select("id", "email", "avatar_url", "full_name", "slug", "bio", "last_login_at", "role", "created_at", "updated_at", suspendedColumn, "suspended_until").
 		From("users").
 		Where(squirrel.Eq{"id": userID}).
 		Where(squirrel.Eq{"deleted_at": nil})
@@ -45,6 +52,8 @@ func (s *Store) GetUserByID(ctx context.Context, userID int) (*models.User, erro
 		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Suspended,
+		&user.SuspendedUntil,
 	)
 
 	switch {
@@ -59,7 +68,7 @@ func (s *Store) GetUserByID(ctx context.Context, userID int) (*models.User, erro
 
 func (s *Store) GetUserBySlug(ctx context.Context, slug string) (*models.User, error) {
 	builder := dbx.StatementBuilder.
-		Select("id", "email", "avatar_url", "full_name", "slug", "bio", "last_login_at", "role", "created_at", "updated_at").
+		Select("id", "email", "avatar_url", "full_name", "slug", "bio", "last_login_at", "role", "created_at", "updated_at", suspendedColumn, "suspended_until").
 		From("users").
 		Where(squirrel.Eq{"slug": slug}).
 		Where(squirrel.Eq{"deleted_at": nil})
@@ -81,6 +90,8 @@ func (s *Store) GetUserBySlug(ctx context.Context, slug string) (*models.User, e
 		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Suspended,
+		&user.SuspendedUntil,
 	)
 
 	switch {
@@ -95,7 +106,7 @@ func (s *Store) GetUserBySlug(ctx context.Context, slug string) (*models.User, e
 
 func (s *Store) GetUserByEmail(ctx context.Context, email string) (*models.UserWithPassword, error) {
 	builder := dbx.StatementBuilder.
-		Select("id", "email", "avatar_url", "full_name", "slug", "bio", "role", "pass_hash", "is_verified", "updated_at", "created_at").
+		Select("id", "email", "avatar_url", "full_name", "slug", "bio", "role", "pass_hash", "is_verified", "updated_at", "created_at", suspendedColumn, "suspended_until").
 		From("users").
 		Where(squirrel.Eq{"email": email}).
 		Where(squirrel.Eq{"deleted_at": nil})
@@ -133,6 +144,8 @@ func (s *Store) GetUserByEmail(ctx context.Context, email string) (*models.UserW
 		&user.IsVerified,
 		&user.User.UpdatedAt,
 		&user.CreatedAt,
+		&user.Suspended,
+		&user.SuspendedUntil,
 	)
 
 	cmd, loginTimeErr := s.db.Exec(ctx, loginAtQuery, loginAtArgs...)
@@ -170,7 +183,18 @@ func (s *Store) CreateUser(ctx context.Context, user *models.UserWithPassword) (
 	)
 	defer span.End()
 
-	err = s.db.QueryRow(ctx, query, args...).Scan(&user.ID, &user.Role, &user.CreatedAt)
+	err = s.WithTx(ctx, func(tx *Store) error {
+		if err := tx.db.QueryRow(ctx, query, args...).Scan(&user.ID, &user.Role, &user.CreatedAt); err != nil {
+			return err
+		}
+
+		event, err := events.UserCreated(user.User)
+		if err != nil {
+			return err
+		}
+
+		return insertOutboxEvent(ctx, tx.db, event)
+	})
 
 	switch {
 	case dbx.IsUniqueViolation(err, "email"):
@@ -186,29 +210,50 @@ func (s *Store) CreateUser(ctx context.Context, user *models.UserWithPassword) (
 	return user.User, nil
 }
 
+// ConfirmUser marks the user's email as verified. Only a guest is promoted to user, through ChangeUserRole
+// so the promotion is recorded in the role history; any other role is left as it is.
 func (s *Store) ConfirmUser(ctx context.Context, userID int64) error {
 	builder := dbx.StatementBuilder.
 		Update("users").
-		Set("role", "user").
 		Set("is_verified", true).
 		Where(squirrel.Eq{"id": userID}).
 		Where(squirrel.Eq{"deleted_at": nil})
 
 	query, args, err := builder.ToSql()
 	if err != nil {
-		return err
+		return apperrors.Internal(err)
 	}
 
-	cmd, err := s.db.Exec(ctx, query, args...)
+	return s.WithTx(ctx, func(tx *Store) error {
+		role, err := tx.lockUserRole(ctx, userID)
+		if err != nil {
+			return err
+		}
 
-	switch {
-	case cmd.RowsAffected() == 0:
-		return apperrors.NotFound("user", "id", userID)
-	case err != nil:
-		return apperrors.Internal(err)
-	}
+		if _, err = tx.db.Exec(ctx, query, args...); err != nil {
+			return apperrors.Internal(err)
+		}
 
-	return nil
+		if role == guestRole {
+			change := &models.RoleChange{UserID: userID, NewRole: verifiedRole, Reason: "email verified"}
+			if err = tx.ChangeUserRole(ctx, change); err != nil {
+				return err
+			}
+
+			role = verifiedRole
+		}
+
+		event, err := events.UserVerified(userID, role)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if err = insertOutboxEvent(ctx, tx.db, event); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		return nil
+	})
 }
 
 func (s *Store) UpdateUser(ctx context.Context, userID int, data *models.UpdateUser) error {
@@ -243,10 +288,26 @@ func (s *Store) UpdateUser(ctx context.Context, userID int, data *models.UpdateU
 		return err
 	}
 
-	cmd, err := s.db.Exec(ctx, query, args...)
+	err = s.WithTx(ctx, func(tx *Store) error {
+		cmd, err := tx.db.Exec(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+
+		if cmd.RowsAffected() == 0 {
+			return errNoRowsAffected
+		}
+
+		event, err := events.UserUpdated(int64(userID), data)
+		if err != nil {
+			return err
+		}
+
+		return insertOutboxEvent(ctx, tx.db, event)
+	})
 
 	switch {
-	case cmd.RowsAffected() == 0:
+	case errors.Is(err, errNoRowsAffected):
 		return apperrors.NotFound("user", "id", userID)
 	case err != nil:
 		return apperrors.Internal(err)
@@ -267,10 +328,26 @@ func (s *Store) UpdatePassword(ctx context.Context, userID int, password string)
 		return err
 	}
 
-	cmd, err := s.db.Exec(ctx, query, args...)
+	err = s.WithTx(ctx, func(tx *Store) error {
+		cmd, err := tx.db.Exec(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+
+		if cmd.RowsAffected() == 0 {
+			return errNoRowsAffected
+		}
+
+		event, err := events.UserPasswordChanged(int64(userID))
+		if err != nil {
+			return err
+		}
+
+		return insertOutboxEvent(ctx, tx.db, event)
+	})
 
 	switch {
-	case cmd.RowsAffected() == 0:
+	case errors.Is(err, errNoRowsAffected):
 		return apperrors.NotFound("user", "id", userID)
 	case err != nil:
 		return apperrors.Internal(err)
@@ -290,10 +367,26 @@ func (s *Store) DeleteUser(ctx context.Context, userID int) error {
 		return err
 	}
 
-	cmd, err := s.db.Exec(ctx, query, args...)
+	err = s.WithTx(ctx, func(tx *Store) error {
+		cmd, err := tx.db.Exec(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+
+		if cmd.RowsAffected() == 0 {
+			return errNoRowsAffected
+		}
+
+		event, err := events.UserDeleted(int64(userID))
+		if err != nil {
+			return err
+		}
+
+		return insertOutboxEvent(ctx, tx.db, event)
+	})
 
 	switch {
-	case cmd.RowsAffected() == 0:
+	case errors.Is(err, errNoRowsAffected):
 		return apperrors.NotFound("user", "id", userID)
 	case err != nil:
 		return apperrors.Internal(err)
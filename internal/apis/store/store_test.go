@@ -0,0 +1,31 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/store/storetest"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"strings"
+	"testing"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	return NewStore(storetest.NewPool(t))
+}
+
+// createTestUser inserts a user whose name, slug and email are derived from name.
+func createTestUser(t *testing.T, s *Store, name string) *models.User {
+	t.Helper()
+
+	user, err := s.CreateUser(context.Background(), &models.UserWithPassword{
+		User:         &models.User{FullName: name, Slug: strings.ToLower(name), Email: strings.ToLower(name) + "@example.com"},
+		UserPassword: &models.UserPassword{PasswordHash: fmt.Sprintf("$2a$04$%053d", 0)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return user
+}
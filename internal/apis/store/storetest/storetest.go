@@ -0,0 +1,92 @@
+// Package storetest provisions throwaway Postgres schemas for tests of the store and the layers above it.
+package storetest
+
+import (
+	"context"
+	"crypto/rand"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// DatabaseEnv names a Postgres the tests may create throwaway schemas in. Tests calling NewPool are
+// skipped when it is not set.
+const DatabaseEnv = "USERS_TEST_POSTGRES_URL"
+
+// NewPool returns a pool on a fresh schema with every migration applied, dropped when the test ends.
+func NewPool(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+
+	url := os.Getenv(DatabaseEnv)
+	if url == "" {
+		t.Skipf("%s is not set", DatabaseEnv)
+	}
+
+	ctx := context.Background()
+	schema := "users_test_" + strings.ToLower(rand.Text()[:12])
+
+	admin, err := pgx.Connect(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close(ctx)
+
+	if _, err = admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		conn, err := pgx.Connect(context.Background(), url)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close(context.Background())
+
+		if _, err = conn.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			t.Error(err)
+		}
+	})
+
+	config, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.ConnConfig.RuntimeParams["search_path"] = schema + ", public"
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	for _, path := range migrations(t) {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		up, _, _ := strings.Cut(string(raw), "---- create above / drop below ----")
+		if _, err = pool.Exec(ctx, up); err != nil {
+			t.Fatalf("migration %s: %v", filepath.Base(path), err)
+		}
+	}
+
+	return pool
+}
+
+// migrations lists the tern migrations in order, found relative to this file so any package can call NewPool.
+func migrations(t testing.TB) []string {
+	_, file, _, _ := runtime.Caller(0)
+
+	paths, err := filepath.Glob(filepath.Join(filepath.Dir(file), "..", "..", "..", "..", "migrations", "*.sql"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no migrations found: %v", err)
+	}
+
+	return paths
+}
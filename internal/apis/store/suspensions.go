@@ -0,0 +1,191 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"github.com/Brain-Wave-Ecosystem/go-common/pkg/dbx"
+	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/events"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/rbac"
+	"github.com/Masterminds/squirrel"
+	"time"
+)
+
+// suspendedColumn computes whether a suspension is in effect, so an expired one stops applying
+// even before the background job clears it.
+const suspendedColumn = "(suspended_at IS NOT NULL AND (suspended_until IS NULL OR suspended_until > NOW()))"
+
+// ErrSuspendAdmin is returned when suspending an admin; they have to be demoted first.
+var ErrSuspendAdmin = errors.New("admins cannot be suspended")
+
+// ErrTargetNotOutranked is returned when the actor's role does not rank above the target's.
+var ErrTargetNotOutranked = errors.New("cannot act on a user whose role is not below yours")
+
+// SuspendUser starts or replaces the user's suspension and enqueues the event. The target's role is checked
+// against actorRole under the row lock, so a concurrent promotion cannot slip past it.
+func (s *Store) SuspendUser(ctx context.Context, suspension *models.Suspension, actorRole rbac.Role) error {
+	return s.WithTx(ctx, func(tx *Store) error {
+		role, err := tx.lockUserRole(ctx, suspension.UserID)
+		if err != nil {
+			return err
+		}
+
+		if role == adminRole {
+			return ErrSuspendAdmin
+		}
+
+		if !actorRole.CanManage(rbac.Role(role)) {
+			return ErrTargetNotOutranked
+		}
+
+		var suspendedBy *int64
+		if suspension.SuspendedBy != 0 {
+			suspendedBy = &suspension.SuspendedBy
+		}
+
+		now := time.Now()
+		builder := dbx.StatementBuilder.
+			Update("users").
+			Set("suspended_at", now).
+			Set("suspended_until", suspension.Until).
+			Set("suspension_reason", suspension.Reason).
+			Set("suspended_by", suspendedBy).
+			Set("updated_at", now).
+			Where(squirrel.Eq{"id": suspension.UserID})
+
+		query, args, err := builder.ToSql()
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if _, err = tx.db.Exec(ctx, query, args...); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		event, err := events.UserSuspended(suspension)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if err = insertOutboxEvent(ctx, tx.db, event); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		return nil
+	})
+}
+
+// UnsuspendUser lifts the user's suspension. Lifting a user who is not suspended is a no-op.
+func (s *Store) UnsuspendUser(ctx context.Context, userID, unsuspendedBy int64, actorRole rbac.Role) error {
+	builder := dbx.StatementBuilder.
+		Update("users").
+		Set("suspended_at", nil).
+		Set("suspended_until", nil).
+		Set("suspension_reason", nil).
+		Set("suspended_by", nil).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": userID}).
+		Where(squirrel.Eq{"deleted_at": nil}).
+		Where(squirrel.NotEq{"suspended_at": nil})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	return s.WithTx(ctx, func(tx *Store) error {
+		role, err := tx.lockUserRole(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if !actorRole.CanManage(rbac.Role(role)) {
+			return ErrTargetNotOutranked
+		}
+
+		cmd, err := tx.db.Exec(ctx, query, args...)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if cmd.RowsAffected() == 0 {
+			return nil
+		}
+
+		event, err := events.UserUnsuspended(userID, unsuspendedBy, false)
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+
+		if err = insertOutboxEvent(ctx, tx.db, event); err != nil {
+			return apperrors.Internal(err)
+		}
+
+		return nil
+	})
+}
+
+// LiftExpiredSuspensions clears suspensions whose end has passed and returns the affected user ids.
+func (s *Store) LiftExpiredSuspensions(ctx context.Context) ([]int64, error) {
+	builder := dbx.StatementBuilder.
+		Update("users").
+		Set("suspended_at", nil).
+		Set("suspended_until", nil).
+		Set("suspension_reason", nil).
+		Set("suspended_by", nil).
+		Set("updated_at", time.Now()).
+		Where(squirrel.NotEq{"suspended_at": nil}).
+		Where(squirrel.LtOrEq{"suspended_until": time.Now()}).
+		Suffix("RETURNING id")
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	var lifted []int64
+
+	err = s.WithTx(ctx, func(tx *Store) error {
+		lifted = lifted[:0]
+
+		rows, err := tx.db.Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+
+		for rows.Next() {
+			var userID int64
+			if err = rows.Scan(&userID); err != nil {
+				rows.Close()
+				return err
+			}
+
+			lifted = append(lifted, userID)
+		}
+
+		rows.Close()
+
+		if err = rows.Err(); err != nil {
+			return err
+		}
+
+		for _, userID := range lifted {
+			event, err := events.UserUnsuspended(userID, 0, true)
+			if err != nil {
+				return err
+			}
+
+			if err = insertOutboxEvent(ctx, tx.db, event); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+
+	return lifted, nil
+}
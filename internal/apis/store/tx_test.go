@@ -0,0 +1,128 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
+	"testing"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "serialization failure", err: &pgconn.PgError{Code: "40001"}, want: true},
+		{name: "deadlock", err: &pgconn.PgError{Code: "40P01"}, want: true},
+		{name: "wrapped", err: fmt.Errorf("update: %w", &pgconn.PgError{Code: "40001"}), want: true},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}, want: false},
+		{name: "other error", err: errors.New("boom"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryable(tt.err); got != tt.want {
+				t.Errorf("isRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithTxRetries(t *testing.T) {
+	s := newTestStore(t)
+	user := createTestUser(t, s, "Retry")
+
+	serialization := &pgconn.PgError{Code: "40001"}
+	permanent := errors.New("permanent")
+
+	tests := []struct {
+		name         string
+		failures     int
+		err          error
+		wantAttempts int
+		wantErr      error
+		wantBio      string
+	}{
+		{name: "commits first time", failures: 0, wantAttempts: 1, wantBio: "commits first time"},
+		{name: "retries serialization failure", failures: 1, err: serialization, wantAttempts: 2, wantBio: "retries serialization failure"},
+		{name: "gives up after max attempts", failures: maxTxAttempts, err: serialization, wantAttempts: maxTxAttempts, wantErr: serialization},
+		{name: "does not retry other errors", failures: 1, err: permanent, wantAttempts: 1, wantErr: permanent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			if _, err := s.db.Exec(ctx, "UPDATE users SET bio = NULL WHERE id = $1", user.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			attempts := 0
+			err := s.WithTx(ctx, func(tx *Store) error {
+				attempts++
+
+				if _, err := tx.db.Exec(ctx, "UPDATE users SET bio = $1 WHERE id = $2", tt.name, user.ID); err != nil {
+					return err
+				}
+
+				if attempts <= tt.failures {
+					return tt.err
+				}
+
+				return nil
+			})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("WithTx = %v, want %v", err, tt.wantErr)
+			}
+
+			if attempts != tt.wantAttempts {
+				t.Errorf("fn ran %d times, want %d", attempts, tt.wantAttempts)
+			}
+
+			var bio string
+			if err = s.db.QueryRow(ctx, "SELECT COALESCE(bio, '') FROM users WHERE id = $1", user.ID).Scan(&bio); err != nil {
+				t.Fatal(err)
+			}
+
+			if bio != tt.wantBio {
+				t.Errorf("bio = %q, want %q", bio, tt.wantBio)
+			}
+		})
+	}
+}
+
+func TestWithTxJoinsOuterTransaction(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	rollback := errors.New("rollback")
+
+	err := s.WithTx(ctx, func(tx *Store) error {
+		return tx.WithTx(ctx, func(inner *Store) error {
+			if inner != tx {
+				t.Error("nested WithTx started a new transaction")
+			}
+
+			createTestUser(t, inner, "Nested")
+
+			return rollback
+		})
+	})
+
+	if !errors.Is(err, rollback) {
+		t.Fatalf("WithTx = %v, want %v", err, rollback)
+	}
+
+	var n int
+	if err = s.db.QueryRow(ctx, "SELECT count(*) FROM users").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 0 {
+		t.Errorf("%d users left after the outer transaction rolled back, want 0", n)
+	}
+}
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"github.com/Brain-Wave-Ecosystem/go-common/pkg/dbx"
+	apperrors "github.com/Brain-Wave-Ecosystem/go-common/pkg/error"
+	"github.com/Masterminds/squirrel"
+	"time"
+)
+
+// FindUserByEmail returns the id and verification flag of an active user without touching last_login_at.
+// found is false when no active user has the email.
+func (s *Store) FindUserByEmail(ctx context.Context, email string) (userID int64, verified, found bool, err error) {
+	builder := dbx.StatementBuilder.
+		Select("id", "COALESCE(is_verified, FALSE)").
+		From("users").
+		Where(squirrel.Eq{"email": email}).
+		Where(squirrel.Eq{"deleted_at": nil})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return 0, false, false, apperrors.Internal(err)
+	}
+
+	err = s.db.QueryRow(ctx, query, args...).Scan(&userID, &verified)
+
+	switch {
+	case dbx.IsNoRows(err):
+		return 0, false, false, nil
+	case err != nil:
+		return 0, false, false, apperrors.Internal(err)
+	}
+
+	return userID, verified, true, nil
+}
+
+func (s *Store) CreateVerificationToken(ctx context.Context, userID int64, tokenHash string, expiresAt time.Time) error {
+	builder := dbx.StatementBuilder.
+		Insert("users_verification_tokens").
+		Columns("user_id", "token_hash", "expires_at").
+		Values(userID, tokenHash, expiresAt)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+
+	if _, err = s.db.Exec(ctx, query, args...); err != nil {
+		return apperrors.Internal(err)
+	}
+
+	return nil
+}
+
+// VerificationTokensSince reports how many tokens were issued to the user after since and when the latest one was.
+func (s *Store) VerificationTokensSince(ctx context.Context, userID int64, since time.Time) (count int, latest *time.Time, err error) {
+	builder := dbx.StatementBuilder.
+		Select("COUNT(*)", "MAX(created_at)").
+		From("users_verification_tokens").
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Gt{"created_at": since})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return 0, nil, apperrors.Internal(err)
+	}
+
+	if err = s.db.QueryRow(ctx, query, args...).Scan(&count, &latest); err != nil {
+		return 0, nil, apperrors.Internal(err)
+	}
+
+	return count, latest, nil
+}
+
+// ConsumeVerificationToken marks an unexpired, unused token as consumed and returns its owner.
+// The single UPDATE guarantees that concurrent attempts with the same token succeed at most once.
+func (s *Store) ConsumeVerificationToken(ctx context.Context, tokenHash string) (int64, error) {
+	now := time.Now()
+
+	builder := dbx.StatementBuilder.
+		Update("users_verification_tokens").
+		Set("consumed_at", now).
+		Where(squirrel.Eq{"token_hash": tokenHash}).
+		Where(squirrel.Eq{"consumed_at": nil}).
+		Where(squirrel.Gt{"expires_at": now}).
+		Suffix("RETURNING user_id")
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return 0, apperrors.Internal(err)
+	}
+
+	var userID int64
+	err = s.db.QueryRow(ctx, query, args...).Scan(&userID)
+
+	switch {
+	case dbx.IsNoRows(err):
+		return 0, apperrors.NotFound("verification token", "token", "provided")
+	case err != nil:
+		return 0, apperrors.Internal(err)
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"context"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// writeTimeout bounds a single access denial insert.
+const writeTimeout = time.Second
+
+type DenialRecorder interface {
+	RecordAccessDenial(ctx context.Context, denial *models.AccessDenial) error
+}
+
+// DenialQueue takes access denials off the request path: RecordAccessDenial only enqueues, and a single
+// worker writes them to the recorder in order. When the queue is full, as under a flood of unauthorized
+// calls, further denials are dropped and counted instead of slowing down or failing requests.
+type DenialQueue struct {
+	recorder DenialRecorder
+	queue    chan *models.AccessDenial
+	dropped  func()
+	logger   *zap.Logger
+
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+}
+
+func NewDenialQueue(recorder DenialRecorder, size int, dropped func(), logger *zap.Logger) *DenialQueue {
+	return &DenialQueue{
+		recorder: recorder,
+		queue:    make(chan *models.AccessDenial, max(size, 1)),
+		dropped:  dropped,
+		logger:   logger,
+	}
+}
+
+// RecordAccessDenial enqueues denial without blocking. It never fails; a denial that does not fit is dropped.
+func (q *DenialQueue) RecordAccessDenial(_ context.Context, denial *models.AccessDenial) error {
+	select {
+	case q.queue <- denial:
+	default:
+		q.dropped()
+	}
+
+	return nil
+}
+
+func (q *DenialQueue) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	q.cancel = cancel
+
+	q.done.Add(1)
+	go func() {
+		defer q.done.Done()
+		q.run(ctx)
+	}()
+}
+
+// Stop waits for the worker to write the denials already queued.
+func (q *DenialQueue) Stop() {
+	if q.cancel == nil {
+		return
+	}
+
+	q.cancel()
+	q.done.Wait()
+}
+
+func (q *DenialQueue) run(ctx context.Context) {
+	for {
+		select {
+		case denial := <-q.queue:
+			q.write(denial)
+		case <-ctx.Done():
+			for {
+				select {
+				case denial := <-q.queue:
+					q.write(denial)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (q *DenialQueue) write(denial *models.AccessDenial) {
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+
+	if err := q.recorder.RecordAccessDenial(ctx, denial); err != nil {
+		q.logger.Error("users-service | failed to record access denial", zap.String("method", denial.Method), zap.Error(err))
+	}
+}
@@ -0,0 +1,72 @@
+package breach
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// hashLength is the length of a hex encoded SHA-1 digest, the key of every corpus line.
+const hashLength = 40
+
+var ErrMalformedLine = errors.New("malformed corpus line")
+
+// Checker tells whether a password is known to have been exposed in a breach.
+type Checker interface {
+	Breached(password string) (bool, error)
+	Close() error
+}
+
+// Open loads a Have I Been Pwned SHA-1 file ordered by hash, or a bloom filter built from one with
+// BuildFilter, whichever path holds. Passwords seen fewer than minCount times are accepted; a filter
+// carries the threshold it was built with instead. An empty path disables the check.
+func Open(path string, minCount int64) (Checker, error) {
+	if path == "" {
+		return disabled{}, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	magic := make([]byte, len(filterMagic))
+	if _, err = io.ReadFull(file, magic); err == nil && bytes.Equal(magic, filterMagic) {
+		_ = file.Close()
+		return LoadFilter(path)
+	}
+
+	return newCorpus(file, minCount)
+}
+
+// Hash returns the uppercase hex SHA-1 digest HIBP corpora are keyed by.
+func Hash(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// parseLine splits a "HASH:COUNT" corpus line.
+func parseLine(line []byte) (hash string, count int64, err error) {
+	hash, rawCount, ok := strings.Cut(strings.TrimRight(string(line), "\r\n"), ":")
+	if !ok || len(hash) != hashLength {
+		return "", 0, ErrMalformedLine
+	}
+
+	count, err = strconv.ParseInt(rawCount, 10, 64)
+	if err != nil {
+		return "", 0, ErrMalformedLine
+	}
+
+	return strings.ToUpper(hash), count, nil
+}
+
+type disabled struct{}
+
+func (disabled) Breached(string) (bool, error) { return false, nil }
+
+func (disabled) Close() error { return nil }
@@ -0,0 +1,204 @@
+package breach
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// writeCorpus writes a HIBP style file for the passwords with their breach counts, ordered by hash.
+func writeCorpus(t *testing.T, counts map[string]int64) string {
+	t.Helper()
+
+	var lines []string
+	for password, count := range counts {
+		lines = append(lines, Hash(password)+":"+strconv.FormatInt(count, 10))
+	}
+	slices.Sort(lines)
+
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+var testCounts = map[string]int64{
+	"password": 9545824,
+	"123456":   37359195,
+	"letmein":  285,
+	"hunter2":  40,
+	"rare":     1,
+	"zzz":      3,
+}
+
+func TestCorpusBreached(t *testing.T) {
+	path := writeCorpus(t, testCounts)
+
+	tests := []struct {
+		name     string
+		minCount int64
+		password string
+		want     bool
+	}{
+		{name: "listed", minCount: 1, password: "letmein", want: true},
+		{name: "first and last lines", minCount: 1, password: "123456", want: true},
+		{name: "seen once", minCount: 1, password: "rare", want: true},
+		{name: "below min count", minCount: 2, password: "rare", want: false},
+		{name: "min count zero means one", minCount: 0, password: "rare", want: true},
+		{name: "not listed", minCount: 1, password: "tangerine-Velvet-87-orbit", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker, err := Open(path, tt.minCount)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer checker.Close()
+
+			got, err := checker.Breached(tt.password)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Breached(%q) = %v, want %v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCorpusCountEveryLine(t *testing.T) {
+	checker, err := Open(writeCorpus(t, testCounts), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer checker.Close()
+
+	corpus := checker.(*Corpus)
+
+	for password, want := range testCounts {
+		if got, err := corpus.Count(Hash(password)); err != nil || got != want {
+			t.Errorf("Count(%q) = %d, %v, want %d", password, got, err, want)
+		}
+	}
+}
+
+func TestFilterBreached(t *testing.T) {
+	dir := t.TempDir()
+	filterPath := filepath.Join(dir, "breach.filter")
+
+	n, err := BuildFilter(writeCorpus(t, testCounts), filterPath, 10, 0.001)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 4 {
+		t.Errorf("BuildFilter added %d hashes, want 4", n)
+	}
+
+	checker, err := Open(filterPath, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer checker.Close()
+
+	if _, ok := checker.(*Filter); !ok {
+		t.Fatalf("Open returned %T, want *Filter", checker)
+	}
+
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{password: "password", want: true},
+		{password: "123456", want: true},
+		{password: "letmein", want: true},
+		{password: "hunter2", want: true},
+		{password: "rare", want: false},
+		{password: "tangerine-Velvet-87-orbit", want: false},
+	}
+
+	for _, tt := range tests {
+		if got, _ := checker.Breached(tt.password); got != tt.want {
+			t.Errorf("Breached(%q) = %v, want %v", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestLoadFilterMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: nil},
+		{name: "wrong magic", data: []byte("NOTBLOOM\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x08\x00")},
+		{name: "truncated bits", data: append(append([]byte{}, filterMagic...), 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 64, 0)},
+		{name: "no hash functions", data: append(append([]byte{}, filterMagic...), 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 8, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "breach.filter")
+			if err := os.WriteFile(path, tt.data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := LoadFilter(path); !errors.Is(err, ErrMalformedFilter) {
+				t.Errorf("LoadFilter = %v, want ErrMalformedFilter", err)
+			}
+		})
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	hash := Hash("password")
+
+	tests := []struct {
+		name      string
+		line      string
+		wantHash  string
+		wantCount int64
+		wantErr   bool
+	}{
+		{name: "crlf", line: hash + ":42\r\n", wantHash: hash, wantCount: 42},
+		{name: "lowercase hash", line: strings.ToLower(hash) + ":7", wantHash: hash, wantCount: 7},
+		{name: "no count", line: hash, wantErr: true},
+		{name: "short hash", line: hash[:39] + ":1", wantErr: true},
+		{name: "bad count", line: hash + ":many", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotHash, gotCount, err := parseLine([]byte(tt.line))
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrMalformedLine) {
+					t.Errorf("parseLine(%q) error = %v, want ErrMalformedLine", tt.line, err)
+				}
+				return
+			}
+
+			if err != nil || gotHash != tt.wantHash || gotCount != tt.wantCount {
+				t.Errorf("parseLine(%q) = %q, %d, %v, want %q, %d", tt.line, gotHash, gotCount, err, tt.wantHash, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestOpenEmptyPathDisables(t *testing.T) {
+	checker, err := Open("", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := checker.Breached("password"); got {
+		t.Error("Breached with the check disabled = true, want false")
+	}
+}
@@ -0,0 +1,113 @@
+package breach
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+)
+
+var _ Checker = (*Corpus)(nil)
+
+// Corpus looks passwords up in a HIBP file ordered by hash with a binary search over byte offsets,
+// so the multi-gigabyte file is never loaded into memory.
+type Corpus struct {
+	file     *os.File
+	size     int64
+	minCount int64
+}
+
+func newCorpus(file *os.File, minCount int64) (*Corpus, error) {
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return &Corpus{file: file, size: info.Size(), minCount: max(minCount, 1)}, nil
+}
+
+func (c *Corpus) Breached(password string) (bool, error) {
+	count, err := c.Count(Hash(password))
+	if err != nil {
+		return false, err
+	}
+
+	return count >= c.minCount, nil
+}
+
+// Count returns how often the hash was seen in breaches, 0 when it is not in the corpus.
+func (c *Corpus) Count(hash string) (int64, error) {
+	// Invariant: if the line for hash exists, it starts within [lo, hi).
+	lo, hi := int64(0), c.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+
+		start, line, err := c.lineAfter(mid)
+		if err != nil {
+			return 0, err
+		}
+
+		if line == nil || start >= hi {
+			hi = mid
+			continue
+		}
+
+		key, count, err := parseLine(line)
+		if err != nil {
+			return 0, err
+		}
+
+		switch {
+		case key < hash:
+			lo = start + int64(len(line))
+		case key > hash:
+			hi = mid
+		default:
+			return count, nil
+		}
+	}
+
+	return 0, nil
+}
+
+func (c *Corpus) Close() error {
+	return c.file.Close()
+}
+
+// lineAfter returns the first line starting at or after pos, including its newline, and its offset.
+// line is nil when there is none.
+func (c *Corpus) lineAfter(pos int64) (int64, []byte, error) {
+	start := pos
+	if pos > 0 {
+		start = pos - 1
+	}
+
+	reader := bufio.NewReader(io.NewSectionReader(c.file, start, c.size-start))
+
+	if pos > 0 {
+		skipped, err := reader.ReadSlice('\n')
+		switch {
+		case errors.Is(err, io.EOF):
+			return 0, nil, nil
+		case err != nil && !errors.Is(err, bufio.ErrBufferFull):
+			return 0, nil, err
+		case errors.Is(err, bufio.ErrBufferFull):
+			return 0, nil, ErrMalformedLine
+		}
+
+		start += int64(len(skipped))
+	}
+
+	line, err := reader.ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, nil, err
+	}
+
+	if len(bytes.TrimSpace(line)) == 0 {
+		return 0, nil, nil
+	}
+
+	return start, line, nil
+}
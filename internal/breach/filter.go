@@ -0,0 +1,185 @@
+package breach
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"os"
+)
+
+var filterMagic = []byte("HIBPBLM1")
+
+var ErrMalformedFilter = errors.New("malformed breach filter")
+
+var _ Checker = (*Filter)(nil)
+
+// Filter is a bloom filter over the SHA-1 hashes of a corpus. It is a fraction of the corpus size
+// and fits in memory, at the price of refusing a small share of passwords that were never breached.
+//
+// File layout: magic, number of hash functions (uint32), number of bits (uint64), bit array; all big endian.
+type Filter struct {
+	hashes uint32
+	bits   uint64
+	data   []byte
+}
+
+func newFilter(n uint64, falsePositiveRate float64) *Filter {
+	n = max(n, 1)
+	bits := uint64(math.Ceil(-float64(n) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	bits = max(bits, 64)
+	hashes := uint32(max(math.Round(float64(bits)/float64(n)*math.Ln2), 1))
+
+	return &Filter{hashes: hashes, bits: bits, data: make([]byte, (bits+7)/8)}
+}
+
+func LoadFilter(path string) (*Filter, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	header := len(filterMagic) + 4 + 8
+	if len(raw) < header || string(raw[:len(filterMagic)]) != string(filterMagic) {
+		return nil, ErrMalformedFilter
+	}
+
+	f := &Filter{
+		hashes: binary.BigEndian.Uint32(raw[len(filterMagic):]),
+		bits:   binary.BigEndian.Uint64(raw[len(filterMagic)+4:]),
+		data:   raw[header:],
+	}
+
+	if f.hashes == 0 || f.bits == 0 || uint64(len(f.data)) != (f.bits+7)/8 {
+		return nil, ErrMalformedFilter
+	}
+
+	return f, nil
+}
+
+func (f *Filter) Breached(password string) (bool, error) {
+	digest, _ := hex.DecodeString(Hash(password))
+	return f.test(digest), nil
+}
+
+func (f *Filter) Close() error {
+	return nil
+}
+
+func (f *Filter) add(digest []byte) {
+	h1, h2 := split(digest)
+	for i := uint64(0); i < uint64(f.hashes); i++ {
+		bit := (h1 + i*h2) % f.bits
+		f.data[bit/8] |= 1 << (bit % 8)
+	}
+}
+
+func (f *Filter) test(digest []byte) bool {
+	h1, h2 := split(digest)
+	for i := uint64(0); i < uint64(f.hashes); i++ {
+		bit := (h1 + i*h2) % f.bits
+		if f.data[bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f *Filter) writeTo(w io.Writer) error {
+	header := make([]byte, 0, len(filterMagic)+12)
+	header = append(header, filterMagic...)
+	header = binary.BigEndian.AppendUint32(header, f.hashes)
+	header = binary.BigEndian.AppendUint64(header, f.bits)
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	_, err := w.Write(f.data)
+	return err
+}
+
+// split derives the two base hashes of double hashing from a SHA-1 digest, which is already uniform.
+func split(digest []byte) (uint64, uint64) {
+	return binary.BigEndian.Uint64(digest[:8]), binary.BigEndian.Uint64(digest[8:16]) | 1
+}
+
+// BuildFilter writes a bloom filter of every corpus hash seen at least minCount times. The corpus is
+// read twice: once to size the filter for falsePositiveRate, once to fill it. It returns the number
+// of hashes added.
+func BuildFilter(corpusPath, filterPath string, minCount int64, falsePositiveRate float64) (uint64, error) {
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		return 0, errors.New("false positive rate must be between 0 and 1")
+	}
+
+	n, err := scanCorpus(corpusPath, minCount, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	filter := newFilter(n, falsePositiveRate)
+
+	if _, err = scanCorpus(corpusPath, minCount, filter.add); err != nil {
+		return 0, err
+	}
+
+	out, err := os.Create(filterPath)
+	if err != nil {
+		return 0, err
+	}
+
+	writer := bufio.NewWriter(out)
+
+	if err = filter.writeTo(writer); err == nil {
+		err = writer.Flush()
+	}
+
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+
+	return n, err
+}
+
+// scanCorpus calls add with the digest of every hash seen at least minCount times and returns how many there were.
+func scanCorpus(path string, minCount int64, add func(digest []byte)) (uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var n uint64
+	digest := make([]byte, hashLength/2)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		hash, count, err := parseLine(scanner.Bytes())
+		if err != nil {
+			return 0, err
+		}
+
+		if count < minCount {
+			continue
+		}
+
+		if add != nil {
+			if _, err = hex.Decode(digest, []byte(hash)); err != nil {
+				return 0, ErrMalformedLine
+			}
+
+			add(digest)
+		}
+
+		n++
+	}
+
+	return n, scanner.Err()
+}
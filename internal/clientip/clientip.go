@@ -0,0 +1,143 @@
+package clientip
+
+import (
+	"context"
+	"fmt"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+const forwardedForKey = "x-forwarded-for"
+
+type ipKey struct{}
+
+// Resolver finds the address of the client behind a request. X-Forwarded-For is only honoured when the
+// connection comes from a trusted proxy, and then read right to left: every hop appends the address it
+// received the request from, so the right-most entry not added by a trusted proxy is the one a client
+// cannot forge. Loopback is always trusted because the REST gateway dials the gRPC server over it.
+type Resolver struct {
+	trusted []netip.Prefix
+}
+
+func NewResolver(trustedProxies []string) (*Resolver, error) {
+	r := &Resolver{
+		trusted: []netip.Prefix{
+			netip.MustParsePrefix("127.0.0.0/8"),
+			netip.MustParsePrefix("::1/128"),
+		},
+	}
+
+	for _, proxy := range trustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		prefix, err := parsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+
+		r.trusted = append(r.trusted, prefix)
+	}
+
+	return r, nil
+}
+
+// Resolve returns the client address of the incoming gRPC request, or "" when it has no peer.
+func (r *Resolver) Resolve(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	addr, ok := parseAddr(p.Addr.String())
+	if !ok {
+		return p.Addr.String()
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	return r.resolve(addr, md.Get(forwardedForKey)).String()
+}
+
+func (r *Resolver) resolve(peerAddr netip.Addr, forwardedFor []string) netip.Addr {
+	client := peerAddr
+	if !r.isTrusted(client) {
+		return client
+	}
+
+	// A header repeated by several proxies is one list in the order the values were added.
+	hops := strings.Split(strings.Join(forwardedFor, ","), ",")
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, ok := parseAddr(strings.TrimSpace(hops[i]))
+		if !ok {
+			// Anything left of a malformed entry cannot be attributed to a trusted hop.
+			return client
+		}
+
+		client = addr
+		if !r.isTrusted(client) {
+			return client
+		}
+	}
+
+	return client
+}
+
+// Trusts reports whether remoteAddr, a bare address or host:port, belongs to a trusted proxy.
+func (r *Resolver) Trusts(remoteAddr string) bool {
+	addr, ok := parseAddr(remoteAddr)
+	return ok && r.isTrusted(addr)
+}
+
+func (r *Resolver) isTrusted(addr netip.Addr) bool {
+	for _, prefix := range r.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func NewContext(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, ipKey{}, ip)
+}
+
+// FromContext returns the address stored by NewContext, or "" when there is none.
+func FromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(ipKey{}).(string)
+	return ip
+}
+
+func parsePrefix(value string) (netip.Prefix, error) {
+	if strings.Contains(value, "/") {
+		return netip.ParsePrefix(value)
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// parseAddr accepts a bare address or host:port, as found in peers and X-Forwarded-For entries.
+func parseAddr(value string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	return addr.Unmap(), true
+}
@@ -0,0 +1,70 @@
+package clientip
+
+import (
+	"context"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"net"
+	"net/netip"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	resolver, err := NewResolver([]string{"10.0.0.0/8", "192.0.2.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		peer         string
+		forwardedFor []string
+		want         string
+	}{
+		{name: "direct client", peer: "203.0.113.9", want: "203.0.113.9"},
+		{name: "untrusted peer cannot forward", peer: "203.0.113.9", forwardedFor: []string{"198.51.100.1"}, want: "203.0.113.9"},
+		{name: "trusted proxy without header", peer: "10.1.1.1", want: "10.1.1.1"},
+		{name: "trusted proxy", peer: "10.1.1.1", forwardedFor: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{name: "spoofed left-most entry", peer: "10.1.1.1", forwardedFor: []string{"1.1.1.1, 198.51.100.1"}, want: "198.51.100.1"},
+		{name: "chain of trusted proxies", peer: "127.0.0.1", forwardedFor: []string{"198.51.100.1, 192.0.2.1", "10.2.2.2"}, want: "198.51.100.1"},
+		{name: "entry with port", peer: "10.1.1.1", forwardedFor: []string{"198.51.100.1:4711"}, want: "198.51.100.1"},
+		{name: "ipv6", peer: "::1", forwardedFor: []string{"2001:db8::1"}, want: "2001:db8::1"},
+		{name: "malformed entry", peer: "10.1.1.1", forwardedFor: []string{"198.51.100.1, unknown"}, want: "10.1.1.1"},
+		{name: "only trusted hops", peer: "10.1.1.1", forwardedFor: []string{"10.3.3.3"}, want: "10.3.3.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolver.resolve(netip.MustParseAddr(tt.peer), tt.forwardedFor)
+			if got.String() != tt.want {
+				t.Errorf("resolve(%s, %q) = %s, want %s", tt.peer, tt.forwardedFor, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveFromContext(t *testing.T) {
+	resolver, err := NewResolver(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := resolver.Resolve(context.Background()); got != "" {
+		t.Errorf("Resolve without a peer = %q, want empty", got)
+	}
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 50000}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(forwardedForKey, "198.51.100.1"))
+
+	if got := resolver.Resolve(ctx); got != "198.51.100.1" {
+		t.Errorf("Resolve = %q, want 198.51.100.1", got)
+	}
+}
+
+func TestNewResolverInvalidProxy(t *testing.T) {
+	for _, proxy := range []string{"10.0.0.0/33", "proxy.internal", "10.0.0"} {
+		if _, err := NewResolver([]string{proxy}); err == nil {
+			t.Errorf("NewResolver(%q) succeeded, want an error", proxy)
+		}
+	}
+}
@@ -1,17 +1,191 @@
 package config
 
-import "github.com/Brain-Wave-Ecosystem/go-common/pkg/config"
+import (
+	"github.com/Brain-Wave-Ecosystem/go-common/pkg/config"
+	"time"
+)
 
 type Config struct {
 	config.DefaultServiceConfig
-	Redis    RedisConfig    `envPrefix:"REDIS_"`
-	Postgres PostgresConfig `envPrefix:"POSTGRES_"`
+	HTTP           HTTPConfig           `envPrefix:"HTTP_"`
+	Ops            OpsConfig            `envPrefix:"OPS_"`
+	Proxy          ProxyConfig          `envPrefix:"PROXY_"`
+	Health         HealthConfig         `envPrefix:"HEALTH_"`
+	Redis          RedisConfig          `envPrefix:"REDIS_"`
+	Postgres       PostgresConfig       `envPrefix:"POSTGRES_"`
+	Pagination     PaginationConfig     `envPrefix:"PAGINATION_"`
+	Verification   VerificationConfig   `envPrefix:"VERIFICATION_"`
+	PasswordReset  PasswordResetConfig  `envPrefix:"PASSWORD_RESET_"`
+	PasswordHash   PasswordHashConfig   `envPrefix:"PASSWORD_HASH_"`
+	PasswordPolicy PasswordPolicyConfig `envPrefix:"PASSWORD_POLICY_"`
+	Breach         BreachConfig         `envPrefix:"BREACH_"`
+	Lockout        LockoutConfig        `envPrefix:"LOCKOUT_"`
+	Session        SessionConfig        `envPrefix:"SESSION_"`
+	MFA            MFAConfig            `envPrefix:"MFA_"`
+	NATS           NATSConfig           `envPrefix:"NATS_"`
+	Outbox         OutboxConfig         `envPrefix:"OUTBOX_"`
+	Suspension     SuspensionConfig     `envPrefix:"SUSPENSION_"`
+	Purge          PurgeConfig          `envPrefix:"PURGE_"`
+	Blob           BlobConfig           `envPrefix:"BLOB_"`
+	Audit          AuditConfig          `envPrefix:"AUDIT_"`
+}
+
+type HTTPConfig struct {
+	Port              int           `env:"PORT" envDefault:"8080"`
+	ReadHeaderTimeout time.Duration `env:"READ_HEADER_TIMEOUT" envDefault:"5s"`
+}
+
+// OpsConfig is the listener for probes and metrics, kept off the public REST port.
+type OpsConfig struct {
+	Port int `env:"PORT" envDefault:"9090"`
+}
+
+type ProxyConfig struct {
+	// TrustedCIDRs lists the load balancers and API gateways whose X-Forwarded-For entries and, on the REST
+	// port, user-id and user-role headers are believed, as addresses or CIDRs. Loopback is always trusted.
+	TrustedCIDRs []string `env:"TRUSTED_CIDRS" envSeparator:","`
+}
+
+type HealthConfig struct {
+	Interval   time.Duration `env:"INTERVAL" envDefault:"5s"`
+	Timeout    time.Duration `env:"TIMEOUT" envDefault:"2s"`
+	DrainDelay time.Duration `env:"DRAIN_DELAY" envDefault:"0s"`
 }
 
 type RedisConfig struct {
-	URL string `env:"URL"`
+	URL      string        `env:"URL"`
+	CacheTTL time.Duration `env:"CACHE_TTL" envDefault:"5m"`
+	Timeout  time.Duration `env:"TIMEOUT" envDefault:"200ms"`
 }
 
 type PostgresConfig struct {
 	URL string `env:"URL"`
 }
+
+type PaginationConfig struct {
+	TokenSecret string `env:"TOKEN_SECRET"`
+}
+
+type VerificationConfig struct {
+	TokenTTL       time.Duration `env:"TOKEN_TTL" envDefault:"24h"`
+	ResendCooldown time.Duration `env:"RESEND_COOLDOWN" envDefault:"1m"`
+	MaxPerDay      int           `env:"MAX_PER_DAY" envDefault:"5"`
+}
+
+type PasswordResetConfig struct {
+	TokenTTL       time.Duration `env:"TOKEN_TTL" envDefault:"1h"`
+	ResendCooldown time.Duration `env:"RESEND_COOLDOWN" envDefault:"1m"`
+}
+
+type PasswordHashConfig struct {
+	// Algorithm hashes new passwords, "argon2id" or "bcrypt"; hashes made with the other one still verify
+	// and are upgraded on the next login, as are hashes made with different parameters.
+	Algorithm         string `env:"ALGORITHM" envDefault:"argon2id"`
+	BcryptCost        int    `env:"BCRYPT_COST" envDefault:"10"`
+	Argon2Memory      uint32 `env:"ARGON2_MEMORY" envDefault:"65536"`
+	Argon2Iterations  uint32 `env:"ARGON2_ITERATIONS" envDefault:"3"`
+	Argon2Parallelism uint8  `env:"ARGON2_PARALLELISM" envDefault:"2"`
+	Argon2SaltLength  uint32 `env:"ARGON2_SALT_LENGTH" envDefault:"16"`
+	Argon2KeyLength   uint32 `env:"ARGON2_KEY_LENGTH" envDefault:"32"`
+	// Workers bounds concurrent hashing; 0 uses GOMAXPROCS. QueueSize more operations may wait for a
+	// worker before further ones are rejected with ResourceExhausted.
+	Workers   int `env:"WORKERS" envDefault:"0"`
+	QueueSize int `env:"QUEUE_SIZE" envDefault:"64"`
+}
+
+type PasswordPolicyConfig struct {
+	MinLength int `env:"MIN_LENGTH" envDefault:"10"`
+	MaxLength int `env:"MAX_LENGTH" envDefault:"128"`
+	// MinCharacterClasses counts lowercase, uppercase, digits and symbols.
+	MinCharacterClasses int     `env:"MIN_CHARACTER_CLASSES" envDefault:"2"`
+	MinEntropyBits      float64 `env:"MIN_ENTROPY_BITS" envDefault:"45"`
+	// HistoryDepth is how many previous passwords may not be reused; 0 allows any. Each costs a full hash
+	// comparison, so it is capped at 24.
+	HistoryDepth int `env:"HISTORY_DEPTH" envDefault:"5"`
+}
+
+type BreachConfig struct {
+	// Path is a HIBP SHA-1 file ordered by hash or a filter built from one with cmd/build-breach-filter.
+	// Empty disables the check, which the server warns about at startup.
+	Path string `env:"PATH"`
+	// MinCount is how many breaches get a password refused; a filter applies the threshold it was built with.
+	MinCount int64 `env:"MIN_COUNT" envDefault:"1"`
+}
+
+type LockoutConfig struct {
+	MaxAccountAttempts int           `env:"MAX_ACCOUNT_ATTEMPTS" envDefault:"5"`
+	MaxIPAttempts      int           `env:"MAX_IP_ATTEMPTS" envDefault:"20"`
+	Window             time.Duration `env:"WINDOW" envDefault:"15m"`
+	Duration           time.Duration `env:"DURATION" envDefault:"15m"`
+	BaseDelay          time.Duration `env:"BASE_DELAY" envDefault:"250ms"`
+	MaxDelay           time.Duration `env:"MAX_DELAY" envDefault:"4s"`
+}
+
+type SessionConfig struct {
+	// RefreshTokenTTL is how long a session survives without being refreshed; every refresh extends it.
+	RefreshTokenTTL time.Duration `env:"REFRESH_TOKEN_TTL" envDefault:"720h"`
+}
+
+type MFAConfig struct {
+	Issuer               string        `env:"ISSUER" envDefault:"Brain-Wave"`
+	EncryptionKey        string        `env:"ENCRYPTION_KEY"`
+	ChallengeTTL         time.Duration `env:"CHALLENGE_TTL" envDefault:"5m"`
+	MaxChallengeAttempts int           `env:"MAX_CHALLENGE_ATTEMPTS" envDefault:"5"`
+	RecoveryCodes        int           `env:"RECOVERY_CODES" envDefault:"10"`
+}
+
+type NATSConfig struct {
+	URL           string `env:"URL"`
+	SubjectPrefix string `env:"SUBJECT_PREFIX" envDefault:"events"`
+}
+
+type OutboxConfig struct {
+	PollInterval    time.Duration `env:"POLL_INTERVAL" envDefault:"1s"`
+	BatchSize       int           `env:"BATCH_SIZE" envDefault:"100"`
+	RetryBackoff    time.Duration `env:"RETRY_BACKOFF" envDefault:"1s"`
+	MaxRetryBackoff time.Duration `env:"MAX_RETRY_BACKOFF" envDefault:"5m"`
+	// Lease is how long a claimed batch stays hidden from other relays; events a crashed relay never
+	// finished are retried once it runs out, so it must exceed the time to publish a full batch.
+	Lease time.Duration `env:"LEASE" envDefault:"1m"`
+	// Retention is how long published events are kept before the sweep deletes them; 0 keeps them forever.
+	Retention     time.Duration `env:"RETENTION" envDefault:"168h"`
+	SweepInterval time.Duration `env:"SWEEP_INTERVAL" envDefault:"1h"`
+}
+
+// Values of SuspensionConfig.Visibility.
+const (
+	SuspensionVisibilityMark = "mark"
+	SuspensionVisibilityHide = "hide"
+)
+
+type SuspensionConfig struct {
+	// Visibility decides how suspended profiles look to other users: "mark" returns them flagged, "hide" as not found.
+	Visibility   string        `env:"VISIBILITY" envDefault:"mark"`
+	LiftInterval time.Duration `env:"LIFT_INTERVAL" envDefault:"1m"`
+}
+
+// Values of PurgeConfig.Mode.
+const (
+	PurgeModeDelete    = "delete"
+	PurgeModeAnonymize = "anonymize"
+)
+
+type PurgeConfig struct {
+	// GracePeriod is how long a deleted account can still be restored before it is purged.
+	GracePeriod time.Duration `env:"GRACE_PERIOD" envDefault:"720h"`
+	Interval    time.Duration `env:"INTERVAL" envDefault:"1h"`
+	BatchSize   int           `env:"BATCH_SIZE" envDefault:"100"`
+	Mode        string        `env:"MODE" envDefault:"anonymize"`
+	// DryRun only logs and counts the accounts that would be purged.
+	DryRun bool `env:"DRY_RUN" envDefault:"false"`
+}
+
+type BlobConfig struct {
+	// Dir is the root of the local blob store holding data exports.
+	Dir string `env:"DIR" envDefault:"./data/blobs"`
+}
+
+type AuditConfig struct {
+	// DenialQueueSize is how many access denials may wait to be written before new ones are dropped.
+	DenialQueueSize int `env:"DENIAL_QUEUE_SIZE" envDefault:"1024"`
+}
@@ -0,0 +1,119 @@
+package docs
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Version is stamped at build time with -ldflags "-X github.com/Brain-Wave-Ecosystem/users-service/internal/docs.Version=...".
+var Version = "dev"
+
+// spec is generated from the users proto annotations by `make openapi`; edit the proto, not this file.
+//
+//go:embed openapi/users.swagger.json
+var spec []byte
+
+//go:embed swagger.html
+var swaggerUI []byte
+
+const errorDefinition = "gatewayErrorBody"
+
+// examples are kept here rather than in the proto so they survive regeneration of the spec.
+var examples = map[string]any{
+	"usersCreateUserRequest": map[string]any{
+		"email":    "jane.doe@example.com",
+		"fullName": "Jane Doe",
+		"password": "correct-horse-battery-staple",
+	},
+	"UsersServiceUpdateUserBody": map[string]any{
+		"fullName":  "Jane A. Doe",
+		"bio":       "Neuroscience student",
+		"avatarUrl": "https://cdn.example.com/avatars/jane.png",
+	},
+}
+
+// errorBody mirrors gateway.ErrorBody, the JSON every failed REST call returns.
+var errorBody = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"code":    map[string]any{"type": "integer", "format": "int32", "description": "HTTP status code."},
+		"status":  map[string]any{"type": "string", "description": "gRPC status code name."},
+		"message": map[string]any{"type": "string"},
+		"details": map[string]any{
+			"type":        "array",
+			"items":       map[string]any{"$ref": "#/definitions/protobufAny"},
+			"description": "Status details such as google.rpc.BadRequest field violations or google.rpc.RetryInfo.",
+		},
+	},
+	"example": map[string]any{
+		"code":    404,
+		"status":  "NotFound",
+		"message": "user with id 42 not found",
+	},
+}
+
+// Handler serves the OpenAPI document at /openapi.json and Swagger UI at / under the given prefix.
+func Handler(prefix string) (http.Handler, error) {
+	document, err := buildSpec()
+	if err != nil {
+		return nil, fmt.Errorf("build openapi spec: %w", err)
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET "+prefix+"/openapi.json", func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = writer.Write(document)
+	})
+
+	mux.HandleFunc("GET "+prefix+"/{$}", func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = writer.Write(swaggerUI)
+	})
+
+	return mux, nil
+}
+
+// buildSpec stamps the build version onto the generated document, attaches the request examples and
+// replaces the generator's rpcStatus error schema with the body the gateway actually writes.
+func buildSpec() ([]byte, error) {
+	var document map[string]any
+	if err := json.Unmarshal(spec, &document); err != nil {
+		return nil, err
+	}
+
+	if info, ok := document["info"].(map[string]any); ok {
+		info["title"] = "Users Service API"
+		info["version"] = Version
+	}
+
+	definitions, ok := document["definitions"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("spec has no definitions")
+	}
+
+	for name, example := range examples {
+		if definition, ok := definitions[name].(map[string]any); ok {
+			definition["example"] = example
+		}
+	}
+
+	definitions[errorDefinition] = errorBody
+
+	paths, _ := document["paths"].(map[string]any)
+	for _, path := range paths {
+		operations, _ := path.(map[string]any)
+		for _, operation := range operations {
+			fields, _ := operation.(map[string]any)
+			responses, _ := fields["responses"].(map[string]any)
+
+			if fallback, ok := responses["default"].(map[string]any); ok {
+				fallback["schema"] = map[string]any{"$ref": "#/definitions/" + errorDefinition}
+			}
+		}
+	}
+
+	return json.Marshal(document)
+}
@@ -0,0 +1,74 @@
+package events
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"time"
+)
+
+// Event types carry the payload schema version; consumers decode Payload with the matching
+// message from the users events proto package.
+const (
+	TypeUserCreated         = "users.user_created.v1"
+	TypeUserVerified        = "users.user_verified.v1"
+	TypeUserUpdated         = "users.user_updated.v1"
+	TypeUserPasswordChanged = "users.user_password_changed.v1"
+	TypeUserDeleted         = "users.user_deleted.v1"
+	TypeAccountLocked       = "users.account_locked.v1"
+	TypeUserRoleChanged     = "users.user_role_changed.v1"
+	TypeUserSuspended       = "users.user_suspended.v1"
+	TypeUserUnsuspended     = "users.user_unsuspended.v1"
+	TypeUserRestored        = "users.user_restored.v1"
+	TypeUserPurged          = "users.user_purged.v1"
+	TypeUserErased          = "users.user_erased.v1"
+)
+
+type Event struct {
+	ID         int64     `json:"id"`
+	Type       string    `json:"type"`
+	UserID     int64     `json:"userId"`
+	Payload    []byte    `json:"payload"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+func New(eventType string, userID int64, payload proto.Message) (*Event, error) {
+	data, err := proto.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Event{
+		Type:       eventType,
+		UserID:     userID,
+		Payload:    data,
+		OccurredAt: time.Now(),
+	}, nil
+}
+
+// Publisher delivers events to consumers. Implementations must only return nil once the
+// event is durably accepted: the outbox relay marks it published and never sends it again.
+type Publisher interface {
+	Publish(ctx context.Context, event *Event) error
+}
+
+var _ Publisher = (*LogPublisher)(nil)
+
+// LogPublisher writes events to the service log; it is used until a broker is configured.
+type LogPublisher struct {
+	logger *zap.Logger
+}
+
+func NewLogPublisher(logger *zap.Logger) *LogPublisher {
+	return &LogPublisher{logger: logger}
+}
+
+func (p *LogPublisher) Publish(_ context.Context, event *Event) error {
+	p.logger.Info("users-service | event published",
+		zap.Int64("id", event.ID),
+		zap.String("type", event.Type),
+		zap.Int64("user_id", event.UserID),
+		zap.Time("occurred_at", event.OccurredAt),
+	)
+	return nil
+}
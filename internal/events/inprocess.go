@@ -0,0 +1,47 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+var _ Publisher = (*InProcessPublisher)(nil)
+
+// InProcessPublisher keeps published events in memory and hands them to subscribers synchronously.
+// It backs tests and single-process setups without a broker.
+type InProcessPublisher struct {
+	mu          sync.RWMutex
+	events      []*Event
+	subscribers []func(ctx context.Context, event *Event)
+}
+
+func NewInProcessPublisher() *InProcessPublisher {
+	return &InProcessPublisher{}
+}
+
+func (p *InProcessPublisher) Subscribe(handler func(ctx context.Context, event *Event)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.subscribers = append(p.subscribers, handler)
+}
+
+func (p *InProcessPublisher) Publish(ctx context.Context, event *Event) error {
+	p.mu.Lock()
+	p.events = append(p.events, event)
+	subscribers := append([]func(ctx context.Context, event *Event){}, p.subscribers...)
+	p.mu.Unlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(ctx, event)
+	}
+
+	return nil
+}
+
+func (p *InProcessPublisher) Events() []*Event {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return append([]*Event(nil), p.events...)
+}
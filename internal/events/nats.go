@@ -0,0 +1,51 @@
+package events
+
+import (
+	"context"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"strconv"
+	"time"
+)
+
+var _ Publisher = (*NATSPublisher)(nil)
+
+const streamName = "USERS_EVENTS"
+
+// NATSPublisher publishes to JetStream on "<prefix>.<event type>" and waits for the stream ack.
+// The outbox id is used as the JetStream message id, so redeliveries after a relay crash are deduplicated.
+type NATSPublisher struct {
+	js     jetstream.JetStream
+	prefix string
+}
+
+// NewNATSPublisher makes sure the stream owned by this service exists before publishing to it.
+func NewNATSPublisher(ctx context.Context, conn *nats.Conn, prefix string) (*NATSPublisher, error) {
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:       streamName,
+		Subjects:   []string{prefix + ".users.>"},
+		Storage:    jetstream.FileStorage,
+		Duplicates: 10 * time.Minute,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &NATSPublisher{js: js, prefix: prefix}, nil
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, event *Event) error {
+	msg := nats.NewMsg(p.prefix + "." + event.Type)
+	msg.Data = event.Payload
+	msg.Header.Set("Event-Type", event.Type)
+	msg.Header.Set("User-Id", strconv.FormatInt(event.UserID, 10))
+	msg.Header.Set("Occurred-At", event.OccurredAt.UTC().Format(time.RFC3339Nano))
+
+	_, err := p.js.PublishMsg(ctx, msg, jetstream.WithMsgID(strconv.FormatInt(event.ID, 10)))
+	return err
+}
@@ -0,0 +1,152 @@
+package events
+
+import (
+	eventsv1 "github.com/Brain-Wave-Ecosystem/users-service/gen/users/events/v1"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+func UserCreated(user *models.User) (*Event, error) {
+	return New(TypeUserCreated, user.ID, &eventsv1.UserCreated{
+		UserId:    user.ID,
+		Email:     user.Email,
+		FullName:  user.FullName,
+		Slug:      user.Slug,
+		Role:      user.Role,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+	})
+}
+
+func UserVerified(userID int64, role string) (*Event, error) {
+	return New(TypeUserVerified, userID, &eventsv1.UserVerified{
+		UserId: userID,
+		Role:   role,
+	})
+}
+
+func UserUpdated(userID int64, data *models.UpdateUser) (*Event, error) {
+	payload := &eventsv1.UserUpdated{
+		UserId:    userID,
+		FullName:  data.FullName,
+		AvatarUrl: data.AvatarURL,
+		Bio:       data.Bio,
+	}
+
+	if data.FullName != nil {
+		payload.Slug = &data.Slug
+	}
+
+	return New(TypeUserUpdated, userID, payload)
+}
+
+func UserPasswordChanged(userID int64) (*Event, error) {
+	return New(TypeUserPasswordChanged, userID, &eventsv1.UserPasswordChanged{UserId: userID})
+}
+
+func UserDeleted(userID int64) (*Event, error) {
+	return New(TypeUserDeleted, userID, &eventsv1.UserDeleted{UserId: userID})
+}
+
+func AccountLocked(userID int64, email string, until time.Time) (*Event, error) {
+	return New(TypeAccountLocked, userID, &eventsv1.AccountLocked{
+		UserId:      userID,
+		Email:       email,
+		LockedUntil: timestamppb.New(until),
+	})
+}
+
+func UserRoleChanged(change *models.RoleChange) (*Event, error) {
+	return New(TypeUserRoleChanged, change.UserID, &eventsv1.UserRoleChanged{
+		UserId:    change.UserID,
+		OldRole:   change.OldRole,
+		NewRole:   change.NewRole,
+		ChangedBy: change.ChangedBy,
+		Reason:    change.Reason,
+	})
+}
+
+func UserSuspended(suspension *models.Suspension) (*Event, error) {
+	payload := &eventsv1.UserSuspended{
+		UserId:      suspension.UserID,
+		Reason:      suspension.Reason,
+		SuspendedBy: suspension.SuspendedBy,
+	}
+
+	if suspension.Until != nil {
+		payload.SuspendedUntil = timestamppb.New(*suspension.Until)
+	}
+
+	return New(TypeUserSuspended, suspension.UserID, payload)
+}
+
+// UserUnsuspended is published both for manual lifts and, with expired set, for suspensions that ran out.
+func UserUnsuspended(userID, unsuspendedBy int64, expired bool) (*Event, error) {
+	return New(TypeUserUnsuspended, userID, &eventsv1.UserUnsuspended{
+		UserId:        userID,
+		UnsuspendedBy: unsuspendedBy,
+		Expired:       expired,
+	})
+}
+
+func UserRestored(userID int64) (*Event, error) {
+	return New(TypeUserRestored, userID, &eventsv1.UserRestored{UserId: userID})
+}
+
+func UserPurged(userID int64, anonymized bool) (*Event, error) {
+	return New(TypeUserPurged, userID, &eventsv1.UserPurged{UserId: userID, Anonymized: anonymized})
+}
+
+// UserErased asks downstream services to purge whatever they hold about the user.
+func UserErased(receipt *models.ErasureReceipt) (*Event, error) {
+	payload := &eventsv1.UserErased{
+		UserId:   receipt.UserID,
+		ErasedAt: timestamppb.New(receipt.ErasedAt),
+	}
+
+	if receipt.RequestedBy != nil {
+		payload.RequestedBy = *receipt.RequestedBy
+	}
+
+	return New(TypeUserErased, receipt.UserID, payload)
+}
+
+// RedactableTypes are the event types whose payloads carry PII and are rewritten by Redact.
+var RedactableTypes = []string{TypeUserCreated, TypeUserUpdated, TypeAccountLocked, TypeUserSuspended}
+
+// Redact returns the payload of an event of eventType with its PII fields cleared, so events of an erased
+// user left in the outbox keep only ids, roles and timestamps. Types outside RedactableTypes are returned as is.
+func Redact(eventType string, payload []byte) ([]byte, error) {
+	var message proto.Message
+
+	switch eventType {
+	case TypeUserCreated:
+		message = &eventsv1.UserCreated{}
+	case TypeUserUpdated:
+		message = &eventsv1.UserUpdated{}
+	case TypeAccountLocked:
+		message = &eventsv1.AccountLocked{}
+	case TypeUserSuspended:
+		message = &eventsv1.UserSuspended{}
+	default:
+		return payload, nil
+	}
+
+	if err := proto.Unmarshal(payload, message); err != nil {
+		return nil, err
+	}
+
+	switch m := message.(type) {
+	case *eventsv1.UserCreated:
+		m.Email, m.FullName, m.Slug = "", "", ""
+	case *eventsv1.UserUpdated:
+		m.FullName, m.Slug, m.AvatarUrl, m.Bio = nil, nil, nil, nil
+	case *eventsv1.AccountLocked:
+		m.Email = ""
+	case *eventsv1.UserSuspended:
+		m.Reason = ""
+	}
+
+	return proto.Marshal(message)
+}
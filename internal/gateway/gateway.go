@@ -0,0 +1,115 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	users "github.com/Brain-Wave-Ecosystem/users-service/gen/users"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/clientip"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/rbac"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"net/http"
+	"strings"
+)
+
+// ErrorBody is the JSON shape of every failed REST call. Details carries the status details, such as
+// field violations or RetryInfo, in their protobuf JSON form with an "@type" key.
+type ErrorBody struct {
+	Code    int               `json:"code"`
+	Status  string            `json:"status"`
+	Message string            `json:"message"`
+	Details []json.RawMessage `json:"details,omitempty"`
+}
+
+// NewMux registers every UsersService RPC on a gateway mux that proxies to the gRPC server behind conn,
+// so REST calls go through the same interceptor chain as native gRPC clients. Caller identity headers
+// are only forwarded from proxies the resolver trusts.
+func NewMux(ctx context.Context, conn *grpc.ClientConn, proxies *clientip.Resolver, logger *zap.Logger) (*runtime.ServeMux, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(headerMatcher),
+		runtime.WithMetadata(identityMetadata(proxies)),
+		runtime.WithErrorHandler(errorHandler(logger)),
+	)
+
+	if err := users.RegisterUsersServiceHandler(ctx, mux, conn); err != nil {
+		return nil, err
+	}
+
+	return mux, nil
+}
+
+// headerMatcher forwards the default permanent headers but never the caller identity keys, which
+// identityMetadata forwards only for trusted proxies.
+func headerMatcher(key string) (string, bool) {
+	if isIdentityHeader(key) {
+		return "", false
+	}
+
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+func isIdentityHeader(key string) bool {
+	key = strings.TrimPrefix(strings.ToLower(key), strings.ToLower(runtime.MetadataHeaderPrefix))
+
+	for _, header := range []string{rbac.UserIDKey, rbac.UserRoleKey} {
+		if strings.EqualFold(key, header) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// identityMetadata passes the caller identity headers set by the upstream API gateway through as gRPC
+// metadata. The RBAC interceptor believes whatever they say, so they are dropped unless the request
+// comes straight from a trusted proxy.
+func identityMetadata(proxies *clientip.Resolver) func(context.Context, *http.Request) metadata.MD {
+	return func(_ context.Context, request *http.Request) metadata.MD {
+		if !proxies.Trusts(request.RemoteAddr) {
+			return nil
+		}
+
+		md := metadata.MD{}
+		for _, key := range []string{rbac.UserIDKey, rbac.UserRoleKey} {
+			if value := request.Header.Get(key); value != "" {
+				md.Set(key, value)
+			}
+		}
+
+		return md
+	}
+}
+
+func errorHandler(logger *zap.Logger) runtime.ErrorHandlerFunc {
+	return func(_ context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, writer http.ResponseWriter, request *http.Request, err error) {
+		st := status.Convert(err)
+		code := runtime.HTTPStatusFromCode(st.Code())
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(code)
+
+		body := ErrorBody{
+			Code:    code,
+			Status:  st.Code().String(),
+			Message: st.Message(),
+		}
+
+		for _, detail := range st.Proto().GetDetails() {
+			raw, err := protojson.Marshal(detail)
+			if err != nil {
+				logger.Warn("failed to marshal gateway error detail", zap.String("type", detail.GetTypeUrl()), zap.Error(err))
+				continue
+			}
+
+			body.Details = append(body.Details, raw)
+		}
+
+		if err = json.NewEncoder(writer).Encode(body); err != nil {
+			logger.Warn("failed to write gateway error response", zap.String("path", request.URL.Path), zap.Error(err))
+		}
+	}
+}
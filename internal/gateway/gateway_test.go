@@ -0,0 +1,77 @@
+package gateway
+
+import (
+	"context"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/clientip"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/rbac"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIdentityMetadata(t *testing.T) {
+	proxies, err := clientip.NewResolver([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	annotate := identityMetadata(proxies)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		wantUserID string
+		wantRole   string
+	}{
+		{name: "trusted proxy", remoteAddr: "10.1.1.1:50000", wantUserID: "7", wantRole: "admin"},
+		{name: "loopback", remoteAddr: "127.0.0.1:50000", wantUserID: "7", wantRole: "admin"},
+		{name: "untrusted client", remoteAddr: "203.0.113.9:50000"},
+		{name: "malformed remote address", remoteAddr: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest("GET", "/v1/users/me", nil)
+			request.RemoteAddr = tt.remoteAddr
+			request.Header.Set(rbac.UserIDKey, "7")
+			request.Header.Set(rbac.UserRoleKey, "admin")
+
+			md := annotate(context.Background(), request)
+
+			if got := first(md.Get(rbac.UserIDKey)); got != tt.wantUserID {
+				t.Errorf("%s = %q, want %q", rbac.UserIDKey, got, tt.wantUserID)
+			}
+
+			if got := first(md.Get(rbac.UserRoleKey)); got != tt.wantRole {
+				t.Errorf("%s = %q, want %q", rbac.UserRoleKey, got, tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestHeaderMatcherDropsIdentity(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{header: "User-Id", want: false},
+		{header: "user-role", want: false},
+		{header: "Grpc-Metadata-User-Id", want: false},
+		{header: "Grpc-Metadata-User-Role", want: false},
+		{header: "Grpc-Metadata-Request-Id", want: true},
+		{header: "Authorization", want: true},
+	}
+
+	for _, tt := range tests {
+		if _, got := headerMatcher(tt.header); got != tt.want {
+			t.Errorf("headerMatcher(%q) forwards = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
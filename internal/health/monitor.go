@@ -0,0 +1,263 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/config"
+	"go.uber.org/zap"
+	grpchealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Check probes one dependency and returns nil when it is usable.
+type Check func(ctx context.Context) error
+
+type dependency struct {
+	check    Check
+	critical bool
+}
+
+// Result is the outcome of the latest probe of a dependency.
+type Result struct {
+	Healthy   bool          `json:"healthy"`
+	Critical  bool          `json:"critical"`
+	Error     string        `json:"error,omitempty"`
+	Latency   time.Duration `json:"latency_ns"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// Report is the body of /readyz.
+type Report struct {
+	Status       string            `json:"status"`
+	Dependencies map[string]Result `json:"dependencies"`
+}
+
+// Monitor periodically probes dependencies and mirrors the aggregate into the gRPC health server,
+// which Consul polls, and into /readyz. Liveness stays independent of dependencies so that an
+// outage of Postgres makes the orchestrator stop routing to us rather than restart us.
+type Monitor struct {
+	grpcHealth *grpchealth.Server
+	services   []string
+	deps       map[string]dependency
+	cfg        config.HealthConfig
+	logger     *zap.Logger
+
+	mu       sync.RWMutex
+	results  map[string]Result
+	draining bool
+
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+}
+
+// NewMonitor reports through grpcHealth for every name in services; the empty name is the server-wide status.
+func NewMonitor(grpcHealth *grpchealth.Server, services []string, cfg config.HealthConfig, logger *zap.Logger) *Monitor {
+	return &Monitor{
+		grpcHealth: grpcHealth,
+		services:   services,
+		deps:       make(map[string]dependency),
+		cfg:        cfg,
+		logger:     logger,
+		results:    make(map[string]Result),
+	}
+}
+
+// Register adds a dependency the service cannot work without: its failure makes the instance not ready.
+// It must be called before Start.
+func (m *Monitor) Register(name string, check Check) {
+	m.deps[name] = dependency{check: check, critical: true}
+}
+
+// RegisterOptional adds a dependency that has a fallback. It is reported in /readyz but never
+// takes the instance out of rotation.
+func (m *Monitor) RegisterOptional(name string, check Check) {
+	m.deps[name] = dependency{check: check}
+}
+
+// Start runs a first round of probes synchronously, so the initial status is already accurate, then keeps probing in the background.
+func (m *Monitor) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+
+	m.probe(ctx)
+
+	m.done.Add(1)
+	go func() {
+		defer m.done.Done()
+		m.run(ctx)
+	}()
+}
+
+func (m *Monitor) Stop() {
+	if m.cancel == nil {
+		return
+	}
+
+	m.cancel()
+	m.done.Wait()
+}
+
+// Drain reports NOT_SERVING from now on regardless of dependencies, letting load balancers move
+// traffic away before the servers stop accepting it.
+func (m *Monitor) Drain() {
+	m.mu.Lock()
+	m.draining = true
+	m.mu.Unlock()
+
+	m.setStatus(grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+}
+
+func (m *Monitor) Ready() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.readyLocked()
+}
+
+func (m *Monitor) ReadyHandler() http.HandlerFunc {
+	return func(writer http.ResponseWriter, _ *http.Request) {
+		m.mu.RLock()
+		ready := m.readyLocked()
+		report := Report{Status: "ok", Dependencies: make(map[string]Result, len(m.results))}
+		for name, result := range m.results {
+			report.Dependencies[name] = result
+		}
+		draining := m.draining
+		m.mu.RUnlock()
+
+		code := http.StatusOK
+		switch {
+		case draining:
+			report.Status, code = "draining", http.StatusServiceUnavailable
+		case !ready:
+			report.Status, code = "unavailable", http.StatusServiceUnavailable
+		}
+
+		writeJSON(writer, code, report)
+	}
+}
+
+// LiveHandler only tells that the process is up and serving HTTP.
+func (m *Monitor) LiveHandler() http.HandlerFunc {
+	return func(writer http.ResponseWriter, _ *http.Request) {
+		writeJSON(writer, http.StatusOK, map[string]string{"status": "ok"})
+	}
+}
+
+func (m *Monitor) run(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.probe(ctx)
+		}
+	}
+}
+
+func (m *Monitor) probe(ctx context.Context) {
+	results := make(map[string]Result, len(m.deps))
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for name, dep := range m.deps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result := m.runCheck(ctx, dep)
+
+			mu.Lock()
+			results[name] = result
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	m.mu.Lock()
+	for name, result := range results {
+		if previous, ok := m.results[name]; !ok || previous.Healthy != result.Healthy {
+			m.logTransition(name, result)
+		}
+	}
+	m.results = results
+	ready := m.readyLocked()
+	m.mu.Unlock()
+
+	if ready {
+		m.setStatus(grpc_health_v1.HealthCheckResponse_SERVING)
+	} else {
+		m.setStatus(grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	}
+}
+
+func (m *Monitor) runCheck(ctx context.Context, dep dependency) Result {
+	ctx, cancel := context.WithTimeout(ctx, m.cfg.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := dep.check(ctx)
+
+	result := Result{Healthy: err == nil, Critical: dep.critical, Latency: time.Since(start), CheckedAt: start}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+func (m *Monitor) readyLocked() bool {
+	if m.draining {
+		return false
+	}
+
+	for _, result := range m.results {
+		if result.Critical && !result.Healthy {
+			return false
+		}
+	}
+
+	return true
+}
+
+// setStatus is a no-op once draining, so a late probe cannot put a stopping instance back in rotation.
+func (m *Monitor) setStatus(status grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	m.mu.RLock()
+	draining := m.draining
+	m.mu.RUnlock()
+
+	if draining && status == grpc_health_v1.HealthCheckResponse_SERVING {
+		return
+	}
+
+	for _, service := range m.services {
+		m.grpcHealth.SetServingStatus(service, status)
+	}
+}
+
+func (m *Monitor) logTransition(name string, result Result) {
+	if result.Healthy {
+		m.logger.Info("users-service | dependency is healthy", zap.String("dependency", name))
+		return
+	}
+
+	m.logger.Error("users-service | dependency is unhealthy", zap.String("dependency", name), zap.String("error", result.Error))
+}
+
+func writeJSON(writer http.ResponseWriter, code int, body any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(code)
+	_ = json.NewEncoder(writer).Encode(body)
+}
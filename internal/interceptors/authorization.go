@@ -0,0 +1,107 @@
+package interceptors
+
+import (
+	"context"
+	users "github.com/Brain-Wave-Ecosystem/users-service/gen/users"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/clientip"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/models"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/rbac"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+var usersServicePrefix = "/" + users.UsersService_ServiceDesc.ServiceName + "/"
+
+// DenialRecorder stores access denials. It is called on the request path, so it should not block on
+// the database; the server uses an audit.DenialQueue.
+type DenialRecorder interface {
+	RecordAccessDenial(ctx context.Context, denial *models.AccessDenial) error
+}
+
+// Authorization enforces rbac.Policy on UsersService RPCs and stores the caller in the context for
+// handlers. Denials are logged and written to the audit trail; other services on the server, such as
+// health checks, are not affected.
+func Authorization(recorder DenialRecorder, logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !strings.HasPrefix(info.FullMethod, usersServicePrefix) {
+			return handler(ctx, req)
+		}
+
+		ctx, err := authorize(ctx, recorder, logger, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// AuthorizationStream is the streaming counterpart of Authorization.
+func AuthorizationStream(recorder DenialRecorder, logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !strings.HasPrefix(info.FullMethod, usersServicePrefix) {
+			return handler(srv, ss)
+		}
+
+		ctx, err := authorize(ss.Context(), recorder, logger, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authorize(ctx context.Context, recorder DenialRecorder, logger *zap.Logger, method string) (context.Context, error) {
+	caller := rbac.CallerFromMD(ctx)
+
+	if ok, reason := rbac.Authorize(method, caller); !ok {
+		auditDenial(ctx, recorder, logger, method, caller, reason)
+
+		if !caller.Authenticated() {
+			return nil, status.Error(codes.Unauthenticated, "authentication required")
+		}
+
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+
+	return rbac.WithCaller(ctx, caller), nil
+}
+
+// contextStream overrides the context of a server stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func auditDenial(ctx context.Context, recorder DenialRecorder, logger *zap.Logger, method string, caller rbac.Caller, reason string) {
+	denial := &models.AccessDenial{
+		Role:     string(caller.Role),
+		Method:   method,
+		Reason:   reason,
+		ClientIP: clientip.FromContext(ctx),
+	}
+
+	if caller.Authenticated() {
+		denial.UserID = &caller.UserID
+	}
+
+	logger.Warn("users-service | access denied",
+		zap.String("method", method),
+		zap.Int64("user_id", caller.UserID),
+		zap.String("role", string(caller.Role)),
+		zap.String("reason", reason),
+		zap.String("client_ip", denial.ClientIP),
+	)
+
+	if err := recorder.RecordAccessDenial(ctx, denial); err != nil {
+		logger.Error("users-service | failed to record access denial", zap.String("method", method), zap.Error(err))
+	}
+}
@@ -0,0 +1,23 @@
+package interceptors
+
+import (
+	"context"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/clientip"
+	"google.golang.org/grpc"
+)
+
+// ClientIP resolves the client address once per RPC and stores it in the context for the lockout,
+// sessions and the access denial audit.
+func ClientIP(resolver *clientip.Resolver) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(clientip.NewContext(ctx, resolver.Resolve(ctx)), req)
+	}
+}
+
+// ClientIPStream is the streaming counterpart of ClientIP.
+func ClientIPStream(resolver *clientip.Resolver) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		return handler(srv, &contextStream{ServerStream: ss, ctx: clientip.NewContext(ctx, resolver.Resolve(ctx))})
+	}
+}
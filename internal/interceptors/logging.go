@@ -0,0 +1,68 @@
+package interceptors
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+const userIDKey = "user-id"
+
+// Logging writes one access log entry per RPC with method, duration, status code and caller id.
+func Logging(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+
+		resp, err := handler(ctx, req)
+		logRPC(ctx, logger, info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+// LoggingStream is the streaming counterpart of Logging, with one entry once the stream ends.
+func LoggingStream(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+
+		err := handler(srv, ss)
+		logRPC(ss.Context(), logger, info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+func logRPC(ctx context.Context, logger *zap.Logger, method string, start time.Time, err error) {
+	fields := []zap.Field{
+		zap.String("method", method),
+		zap.Duration("duration", time.Since(start)),
+		zap.String("code", status.Code(err).String()),
+	}
+
+	if userID := userIDFromMD(ctx); userID != "" {
+		fields = append(fields, zap.String("user_id", userID))
+	}
+
+	if err != nil {
+		logger.Warn("users-service | rpc failed", append(fields, zap.Error(err))...)
+	} else {
+		logger.Info("users-service | rpc handled", fields...)
+	}
+}
+
+func userIDFromMD(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(userIDKey)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
@@ -0,0 +1,35 @@
+package interceptors
+
+import (
+	"context"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// Metrics records the latency and status code of every RPC. It sits first in the chain so that
+// panics turned into Internal by Recovery are counted too.
+func Metrics(m *metrics.Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+
+		resp, err := handler(ctx, req)
+
+		m.ObserveRPC(info.FullMethod, status.Code(err).String(), time.Since(start))
+
+		return resp, err
+	}
+}
+
+func MetricsStream(m *metrics.Metrics) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+
+		err := handler(srv, ss)
+
+		m.ObserveRPC(info.FullMethod, status.Code(err).String(), time.Since(start))
+
+		return err
+	}
+}
@@ -0,0 +1,67 @@
+package jobs
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// Periodic runs a maintenance task on a fixed interval until stopped. Runs never overlap: a slow
+// run delays the next tick instead of stacking up.
+type Periodic struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context) error
+	logger   *zap.Logger
+
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+}
+
+func NewPeriodic(name string, interval time.Duration, run func(ctx context.Context) error, logger *zap.Logger) *Periodic {
+	return &Periodic{
+		name:     name,
+		interval: interval,
+		run:      run,
+		logger:   logger,
+	}
+}
+
+func (p *Periodic) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	p.done.Add(1)
+	go func() {
+		defer p.done.Done()
+		p.loop(ctx)
+	}()
+}
+
+// Stop cancels the run in flight and waits for it to return.
+func (p *Periodic) Stop() {
+	if p.cancel == nil {
+		return
+	}
+
+	p.cancel()
+	p.done.Wait()
+}
+
+func (p *Periodic) loop(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := p.run(ctx); err != nil && ctx.Err() == nil {
+			p.logger.Error("users-service | periodic job failed", zap.String("job", p.name), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"time"
+)
+
+// Metrics owns the service registry. Names and labels are listed in names.go.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	rpcDuration   *prometheus.HistogramVec
+	hashDuration  *prometheus.HistogramVec
+	hashWait      prometheus.Histogram
+	hashRejects   prometheus.Counter
+	registrations prometheus.Counter
+	logins        *prometheus.CounterVec
+	verifications prometheus.Counter
+	deletions     prometheus.Counter
+	purges        *prometheus.CounterVec
+	deniesDropped prometheus.Counter
+}
+
+func New(pool *pgxpool.Pool) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		rpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    RPCDuration,
+			Help:    "Latency of unary gRPC calls.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{LabelMethod, LabelCode}),
+		hashDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    PasswordHashDuration,
+			Help:    "Time spent hashing and comparing passwords.",
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 10),
+		}, []string{LabelOperation}),
+		hashWait: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    PasswordHashQueueWait,
+			Help:    "Time password hashing work waited for a free worker.",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+		}),
+		hashRejects: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: PasswordHashRejections,
+			Help: "Password hashing work rejected because the queue was full.",
+		}),
+		registrations: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: Registrations,
+			Help: "Accounts created.",
+		}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: Logins,
+			Help: "Password login attempts by result.",
+		}, []string{LabelResult}),
+		verifications: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: Verifications,
+			Help: "Email addresses verified.",
+		}),
+		deletions: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: Deletions,
+			Help: "Accounts deleted.",
+		}),
+		purges: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: Purges,
+			Help: "Soft-deleted accounts processed by the purge worker.",
+		}, []string{LabelMode, LabelResult}),
+		deniesDropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: AccessDenialsDropped,
+			Help: "Access denials left out of the audit trail because its queue was full.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		newPoolCollector(pool),
+		m.rpcDuration,
+		m.hashDuration,
+		m.hashWait,
+		m.hashRejects,
+		m.registrations,
+		m.logins,
+		m.verifications,
+		m.deletions,
+		m.purges,
+		m.deniesDropped,
+	)
+
+	return m
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+func (m *Metrics) ObserveRPC(method, code string, elapsed time.Duration) {
+	m.rpcDuration.WithLabelValues(method, code).Observe(elapsed.Seconds())
+}
+
+func (m *Metrics) ObservePasswordHash(operation string, elapsed time.Duration) {
+	m.hashDuration.WithLabelValues(operation).Observe(elapsed.Seconds())
+}
+
+func (m *Metrics) ObservePasswordQueueWait(elapsed time.Duration) {
+	m.hashWait.Observe(elapsed.Seconds())
+}
+
+func (m *Metrics) PasswordHashRejected() {
+	m.hashRejects.Inc()
+}
+
+func (m *Metrics) Registered() {
+	m.registrations.Inc()
+}
+
+func (m *Metrics) Login(result string) {
+	m.logins.WithLabelValues(result).Inc()
+}
+
+func (m *Metrics) Verified() {
+	m.verifications.Inc()
+}
+
+func (m *Metrics) Deleted() {
+	m.deletions.Inc()
+}
+
+func (m *Metrics) Purged(mode, result string) {
+	m.purges.WithLabelValues(mode, result).Inc()
+}
+
+func (m *Metrics) AccessDenialDropped() {
+	m.deniesDropped.Inc()
+}
@@ -0,0 +1,70 @@
+package metrics
+
+// Metric names exported on /metrics. Dashboards and alerts are built on them, so treat a rename
+// like an API change.
+const (
+	// RPCDuration is a histogram of unary RPC latency, labelled by LabelMethod and LabelCode.
+	RPCDuration = "users_service_rpc_duration_seconds"
+	// PasswordHashDuration is a histogram of password hashing work, labelled by LabelOperation.
+	PasswordHashDuration = "users_service_password_hash_duration_seconds"
+	// PasswordHashQueueWait is a histogram of time password hashing work waited for a free worker.
+	PasswordHashQueueWait = "users_service_password_hash_queue_wait_seconds"
+	// PasswordHashRejections is a counter of password hashing work rejected because the queue was full.
+	PasswordHashRejections = "users_service_password_hash_rejections_total"
+
+	// DBPoolAcquiredConns is a gauge of connections currently checked out of the Postgres pool.
+	DBPoolAcquiredConns = "users_service_db_pool_acquired_connections"
+	// DBPoolIdleConns is a gauge of idle connections in the Postgres pool.
+	DBPoolIdleConns = "users_service_db_pool_idle_connections"
+	// DBPoolTotalConns is a gauge of all open connections in the Postgres pool.
+	DBPoolTotalConns = "users_service_db_pool_connections"
+	// DBPoolMaxConns is a gauge of the configured Postgres pool size.
+	DBPoolMaxConns = "users_service_db_pool_max_connections"
+	// DBPoolAcquireWait is a counter of seconds spent waiting for a connection on an exhausted pool.
+	DBPoolAcquireWait = "users_service_db_pool_acquire_wait_seconds_total"
+	// DBPoolEmptyAcquires is a counter of acquires that had to wait because the pool was exhausted.
+	DBPoolEmptyAcquires = "users_service_db_pool_empty_acquires_total"
+
+	// Registrations is a counter of created accounts.
+	Registrations = "users_service_registrations_total"
+	// Logins is a counter of password logins, labelled by LabelResult.
+	Logins = "users_service_logins_total"
+	// Verifications is a counter of confirmed email addresses.
+	Verifications = "users_service_email_verifications_total"
+	// Deletions is a counter of deleted accounts.
+	Deletions = "users_service_deletions_total"
+	// AccessDenialsDropped is a counter of access denials left out of the audit trail because its queue was full.
+	AccessDenialsDropped = "users_service_access_denials_dropped_total"
+	// Purges is a counter of soft-deleted accounts processed by the purge worker, labelled by
+	// LabelMode and LabelResult.
+	Purges = "users_service_purges_total"
+)
+
+// Label names.
+const (
+	// LabelMethod is the full gRPC method, e.g. /users.UsersService/CreateUser.
+	LabelMethod = "method"
+	// LabelCode is the gRPC status code name, e.g. OK or NotFound.
+	LabelCode = "code"
+	// LabelOperation is one of the Operation* values.
+	LabelOperation = "operation"
+	// LabelResult is one of the Result* values.
+	LabelResult = "result"
+	// LabelMode is the purge mode, delete or anonymize.
+	LabelMode = "mode"
+)
+
+// Values of LabelOperation.
+const (
+	OperationHash    = "hash"
+	OperationCompare = "compare"
+)
+
+// Values of LabelResult.
+const (
+	ResultSucceeded = "succeeded"
+	ResultFailed    = "failed"
+	ResultLocked    = "locked"
+	ResultSuspended = "suspended"
+	ResultDryRun    = "dry_run"
+)
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector reads pgxpool statistics at scrape time instead of polling them in the background.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquired      *prometheus.Desc
+	idle          *prometheus.Desc
+	total         *prometheus.Desc
+	max           *prometheus.Desc
+	acquireWait   *prometheus.Desc
+	emptyAcquires *prometheus.Desc
+}
+
+func newPoolCollector(pool *pgxpool.Pool) *poolCollector {
+	return &poolCollector{
+		pool:          pool,
+		acquired:      prometheus.NewDesc(DBPoolAcquiredConns, "Connections currently in use.", nil, nil),
+		idle:          prometheus.NewDesc(DBPoolIdleConns, "Idle connections.", nil, nil),
+		total:         prometheus.NewDesc(DBPoolTotalConns, "Open connections.", nil, nil),
+		max:           prometheus.NewDesc(DBPoolMaxConns, "Maximum pool size.", nil, nil),
+		acquireWait:   prometheus.NewDesc(DBPoolAcquireWait, "Seconds spent waiting for a connection on an exhausted pool.", nil, nil),
+		emptyAcquires: prometheus.NewDesc(DBPoolEmptyAcquires, "Acquires that waited because the pool was exhausted.", nil, nil),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquired
+	ch <- c.idle
+	ch <- c.total
+	ch <- c.max
+	ch <- c.acquireWait
+	ch <- c.emptyAcquires
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.max, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireWait, prometheus.CounterValue, stat.EmptyAcquireWaitTime().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+}
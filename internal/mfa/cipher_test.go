@@ -0,0 +1,60 @@
+package mfa
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCipher(t *testing.T) {
+	c, err := NewCipher(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext, err := c.Encrypt(rfc6238Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := bytes.Clone(ciphertext)
+	tampered[len(tampered)-1] ^= 1
+
+	other, _ := NewCipher(bytes.Repeat([]byte{8}, 32))
+
+	tests := []struct {
+		name       string
+		cipher     *Cipher
+		ciphertext []byte
+		wantErr    bool
+	}{
+		{name: "round trip", cipher: c, ciphertext: ciphertext},
+		{name: "tampered", cipher: c, ciphertext: tampered, wantErr: true},
+		{name: "other key", cipher: other, ciphertext: ciphertext, wantErr: true},
+		{name: "too short", cipher: c, ciphertext: ciphertext[:4], wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plaintext, err := tt.cipher.Decrypt(tt.ciphertext)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Decrypt succeeded, want an error")
+				}
+				return
+			}
+
+			if err != nil || !bytes.Equal(plaintext, rfc6238Secret) {
+				t.Errorf("Decrypt = %q, %v, want %q", plaintext, err, rfc6238Secret)
+			}
+		})
+	}
+}
+
+func TestNewCipherKeyLength(t *testing.T) {
+	for _, size := range []int{0, 16, 31, 33} {
+		if _, err := NewCipher(make([]byte, size)); err == nil {
+			t.Errorf("NewCipher with a %d byte key succeeded, want an error", size)
+		}
+	}
+}
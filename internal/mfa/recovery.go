@@ -0,0 +1,37 @@
+package mfa
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/tokens"
+	"strings"
+)
+
+const recoveryCodeBytes = 5
+
+// GenerateRecoveryCodes returns n one-time codes formatted as "xxxx-xxxx" and their hashes for storage.
+func GenerateRecoveryCodes(n int) (codes, hashes []string, err error) {
+	codes = make([]string, 0, n)
+	hashes = make([]string, 0, n)
+
+	for range n {
+		raw := make([]byte, recoveryCodeBytes)
+		if _, err = rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(raw))
+		code = code[:4] + "-" + code[4:]
+
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode normalizes case and separators so users can type codes loosely.
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return tokens.Hash(normalized)
+}
@@ -0,0 +1,49 @@
+package mfa
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(codes) != 10 || len(hashes) != 10 {
+		t.Fatalf("GenerateRecoveryCodes(10) returned %d codes and %d hashes", len(codes), len(hashes))
+	}
+
+	format := regexp.MustCompile(`^[a-z2-7]{4}-[a-z2-7]{4}$`)
+	seen := make(map[string]bool)
+
+	for i, code := range codes {
+		if !format.MatchString(code) {
+			t.Errorf("code %q does not look like xxxx-xxxx", code)
+		}
+
+		if seen[code] {
+			t.Errorf("code %q generated twice", code)
+		}
+		seen[code] = true
+
+		if hashes[i] != HashRecoveryCode(code) {
+			t.Errorf("hash of code %q does not match HashRecoveryCode", code)
+		}
+	}
+}
+
+func TestHashRecoveryCodeNormalizes(t *testing.T) {
+	want := HashRecoveryCode("abcd-efgh")
+
+	for _, code := range []string{"abcdefgh", "ABCD-EFGH", "abcd efgh", " abcd-efgh "} {
+		if got := HashRecoveryCode(code); got != want {
+			t.Errorf("HashRecoveryCode(%q) differs from HashRecoveryCode(%q)", code, "abcd-efgh")
+		}
+	}
+
+	if HashRecoveryCode("abcd-efgi") == want {
+		t.Error("different codes hash the same")
+	}
+}
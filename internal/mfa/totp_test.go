@@ -0,0 +1,56 @@
+package mfa
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 key of the RFC 6238 test vectors.
+var rfc6238Secret = []byte("12345678901234567890")
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		now      time.Time
+		skew     int
+		wantStep int64
+		wantOK   bool
+	}{
+		// The RFC lists 8 digit codes; these are their last 6 digits.
+		{name: "rfc vector 59", code: "287082", now: time.Unix(59, 0), wantStep: 1, wantOK: true},
+		{name: "rfc vector 1111111109", code: "081804", now: time.Unix(1111111109, 0), wantStep: 37037036, wantOK: true},
+		{name: "rfc vector 1234567890", code: "005924", now: time.Unix(1234567890, 0), wantStep: 41152263, wantOK: true},
+		{name: "previous step within skew", code: "081804", now: time.Unix(1111111109+30, 0), skew: 1, wantStep: 37037036, wantOK: true},
+		{name: "previous step without skew", code: "081804", now: time.Unix(1111111109+30, 0), skew: 0},
+		{name: "outside skew", code: "081804", now: time.Unix(1111111109+90, 0), skew: 1},
+		{name: "wrong code", code: "000000", now: time.Unix(59, 0), skew: 1},
+		{name: "wrong length", code: "94287082", now: time.Unix(59, 0), skew: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfc6238Secret, tt.code, tt.now, tt.skew)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("Validate(%q) = %d, %v, want %d, %v", tt.code, step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Brain Wave", "ana@example.com", rfc6238Secret)
+
+	for _, want := range []string{
+		"otpauth://totp/Brain%20Wave:ana@example.com?",
+		"secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		"issuer=Brain+Wave",
+		"digits=6",
+		"period=30",
+	} {
+		if !strings.Contains(uri, want) {
+			t.Errorf("URI() = %q, want it to contain %q", uri, want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package models
+
+import "time"
+
+// UserExportVersion is bumped whenever the archive layout changes in a way readers must know about.
+const UserExportVersion = 1
+
+// UserExport is everything the service stores about one account, as handed out for a data subject
+// access request. Secrets (password hashes, MFA secrets, token hashes) are never included.
+type UserExport struct {
+	Version            int                   `json:"version"`
+	GeneratedAt        time.Time             `json:"generatedAt"`
+	Account            *ExportAccount        `json:"account"`
+	PasswordChanges    []time.Time           `json:"passwordChanges"`
+	RoleChanges        []*ExportRoleChange   `json:"roleChanges"`
+	MFA                *ExportMFA            `json:"mfa,omitempty"`
+	EmailVerifications []*ExportToken        `json:"emailVerifications"`
+	PasswordResets     []*ExportToken        `json:"passwordResets"`
+	AccessDenials      []*ExportAccessDenial `json:"accessDenials"`
+	Sessions           []*ExportSession      `json:"sessions"`
+}
+
+// ExportAccount is the users row. LastLoginAt is the only login history the service keeps.
+type ExportAccount struct {
+	ID                int64      `json:"id"`
+	Email             string     `json:"email"`
+	FullName          string     `json:"fullName"`
+	Slug              string     `json:"slug"`
+	AvatarURL         *string    `json:"avatarUrl,omitempty"`
+	Bio               *string    `json:"bio,omitempty"`
+	Role              string     `json:"role"`
+	IsVerified        *bool      `json:"isVerified,omitempty"`
+	ProfileVisibility string     `json:"profileVisibility"`
+	CreatedAt         time.Time  `json:"createdAt"`
+	UpdatedAt         *time.Time `json:"updatedAt,omitempty"`
+	LastLoginAt       *time.Time `json:"lastLoginAt,omitempty"`
+	DeletedAt         *time.Time `json:"deletedAt,omitempty"`
+	SuspendedAt       *time.Time `json:"suspendedAt,omitempty"`
+	SuspendedUntil    *time.Time `json:"suspendedUntil,omitempty"`
+	SuspensionReason  *string    `json:"suspensionReason,omitempty"`
+}
+
+type ExportRoleChange struct {
+	OldRole   string    `json:"oldRole"`
+	NewRole   string    `json:"newRole"`
+	ChangedBy *int64    `json:"changedBy,omitempty"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type ExportMFA struct {
+	ConfirmedAt         *time.Time `json:"confirmedAt,omitempty"`
+	CreatedAt           time.Time  `json:"createdAt"`
+	UnusedRecoveryCodes int        `json:"unusedRecoveryCodes"`
+}
+
+type ExportToken struct {
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	ConsumedAt *time.Time `json:"consumedAt,omitempty"`
+}
+
+type ExportAccessDenial struct {
+	Method    string    `json:"method"`
+	Reason    string    `json:"reason"`
+	ClientIP  *string   `json:"clientIp,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type ExportSession struct {
+	UserAgent    string     `json:"userAgent"`
+	IP           string     `json:"ip"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	LastUsedAt   time.Time  `json:"lastUsedAt"`
+	ExpiresAt    time.Time  `json:"expiresAt"`
+	RevokedAt    *time.Time `json:"revokedAt,omitempty"`
+	RevokeReason *string    `json:"revokeReason,omitempty"`
+}
+
+type ExportLocation struct {
+	Location  string
+	SizeBytes int64
+	SHA256    string
+}
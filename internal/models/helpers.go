@@ -5,6 +5,7 @@ import (
 	users "github.com/Brain-Wave-Ecosystem/users-service/gen/users"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"strings"
+	"time"
 	"unicode"
 )
 
@@ -29,9 +30,55 @@ func (u *User) ToGRPC() *users.User {
 		user.UpdatedAt = timestamppb.New(*u.UpdatedAt)
 	}
 
+	if u.Suspended {
+		user.Suspended = true
+
+		if u.SuspendedUntil != nil {
+			user.SuspendedUntil = timestamppb.New(*u.SuspendedUntil)
+		}
+	}
+
 	return user
 }
 
+func (h *UserSearchHit) ToGRPC() *users.UserSearchHit {
+	return &users.UserSearchHit{
+		User:         h.User.ToGRPC(),
+		MatchedField: h.MatchedField,
+		Highlight:    h.Highlight,
+		Rank:         h.Rank,
+	}
+}
+
+func (r *LoginResult) ToGRPC() *users.LoginUserResponse {
+	if r.Challenge != nil {
+		return &users.LoginUserResponse{MfaChallenge: &users.MfaChallenge{
+			ChallengeId: r.Challenge.ID,
+			ExpiresAt:   timestamppb.New(r.Challenge.ExpiresAt),
+		}}
+	}
+
+	response := &users.LoginUserResponse{User: r.User.ToGRPC()}
+
+	if r.Session != nil {
+		response.Session = r.Session.Session.ToGRPC()
+		response.RefreshToken = r.Session.RefreshToken
+	}
+
+	return response
+}
+
+func (s *Session) ToGRPC() *users.Session {
+	return &users.Session{
+		Id:         s.ID,
+		UserAgent:  s.UserAgent,
+		Ip:         s.IP,
+		CreatedAt:  timestamppb.New(s.CreatedAt),
+		LastUsedAt: timestamppb.New(s.LastUsedAt),
+		ExpiresAt:  timestamppb.New(s.ExpiresAt),
+	}
+}
+
 func ToUserWithPassword(r *users.CreateUserRequest) *UserWithPassword {
 	return &UserWithPassword{
 		User: &User{
@@ -44,6 +91,21 @@ func ToUserWithPassword(r *users.CreateUserRequest) *UserWithPassword {
 	}
 }
 
+func ToListUsersFilter(r *users.ListUsersRequest) *ListUsersFilter {
+	return &ListUsersFilter{
+		Role:            r.Role,
+		IsVerified:      r.IsVerified,
+		Deleted:         r.GetDeleted(),
+		CreatedAfter:    toTime(r.GetCreatedAfter()),
+		CreatedBefore:   toTime(r.GetCreatedBefore()),
+		LastLoginAfter:  toTime(r.GetLastLoginAfter()),
+		LastLoginBefore: toTime(r.GetLastLoginBefore()),
+		SortBy:          toUsersSortField(r.GetSortBy()),
+		Descending:      r.GetDescending(),
+		Limit:           int(r.GetPageSize()),
+	}
+}
+
 func (u *UserWithPassword) PrepareUser() *UserWithPassword {
 	u.FullName = prepareFullName(u.FullName)
 	u.Slug = helpers.GenerateSlug(u.FullName)
@@ -73,3 +135,23 @@ func prepareFullName(fullName string) string {
 
 	return strings.Join(names, " ")
 }
+
+func toUsersSortField(field users.UserSortField) UsersSortField {
+	switch field {
+	case users.UserSortField_USER_SORT_FIELD_FULL_NAME:
+		return UsersSortByFullName
+	case users.UserSortField_USER_SORT_FIELD_LAST_LOGIN_AT:
+		return UsersSortByLastLoginAt
+	default:
+		return UsersSortByCreatedAt
+	}
+}
+
+func toTime(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+
+	t := ts.AsTime()
+	return &t
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Reasons recorded when a session is revoked.
+const (
+	SessionRevokedByUser          = "revoked"
+	SessionRevokedPasswordChanged = "password_changed"
+	SessionRevokedTokenReuse      = "refresh_token_reused"
+)
+
+// Session is one signed-in device. Its refresh token is rotated on every use; ExpiresAt moves
+// forward with each rotation.
+type Session struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"userId"`
+	UserAgent  string    `json:"userAgent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// IssuedSession pairs a session with its current refresh token, which only exists in plain text
+// at the moment it is issued.
+type IssuedSession struct {
+	Session      *Session
+	RefreshToken string
+}
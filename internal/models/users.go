@@ -14,6 +14,10 @@ type User struct {
 	IsVerified  *bool      `json:"isVerified,omitempty"`
 	CreatedAt   time.Time  `json:"createdAt"`
 	UpdatedAt   *time.Time `json:"updatedAt,omitempty"`
+
+	// Suspended is true while a suspension is in effect; SuspendedUntil is nil for a permanent ban.
+	Suspended      bool       `json:"suspended,omitempty"`
+	SuspendedUntil *time.Time `json:"suspendedUntil,omitempty"`
 }
 
 type UserWithPassword struct {
@@ -37,3 +41,112 @@ type UpdateUser struct {
 	FullName  *string `json:"fullName,omitempty"`
 	Bio       *string `json:"bio,omitempty"`
 }
+
+type UsersSortField string
+
+const (
+	UsersSortByCreatedAt   UsersSortField = "created_at"
+	UsersSortByFullName    UsersSortField = "full_name"
+	UsersSortByLastLoginAt UsersSortField = "last_login_at"
+)
+
+type ListUsersFilter struct {
+	Role            *string
+	IsVerified      *bool
+	Deleted         bool
+	CreatedAfter    *time.Time
+	CreatedBefore   *time.Time
+	LastLoginAfter  *time.Time
+	LastLoginBefore *time.Time
+	SortBy          UsersSortField
+	Descending      bool
+	Limit           int
+	Cursor          *UsersCursor
+}
+
+// UsersCursor is the keyset position of the last user on a page: the value of the
+// sort column and the id used as a tie-breaker.
+type UsersCursor struct {
+	SortBy     UsersSortField `json:"s"`
+	Descending bool           `json:"d"`
+	Value      string         `json:"v"`
+	ID         int64          `json:"i"`
+}
+
+const (
+	SearchFieldFullName = "full_name"
+	SearchFieldSlug     = "slug"
+	SearchFieldEmail    = "email"
+)
+
+type UserSearchHit struct {
+	User         *User   `json:"user"`
+	MatchedField string  `json:"matchedField"`
+	Highlight    string  `json:"highlight"`
+	Rank         float64 `json:"rank"`
+}
+
+// SearchCursor pins a page token to the query it was issued for.
+type SearchCursor struct {
+	Query  string `json:"q"`
+	Offset int    `json:"o"`
+}
+
+type UserMFA struct {
+	UserID          int64      `json:"userId"`
+	SecretEncrypted []byte     `json:"-"`
+	LastUsedStep    *int64     `json:"-"`
+	ConfirmedAt     *time.Time `json:"confirmedAt,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+}
+
+func (m *UserMFA) Enabled() bool {
+	return m != nil && m.ConfirmedAt != nil
+}
+
+type MFAChallenge struct {
+	ID        string    `json:"id"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// LoginResult holds either the authenticated user with their new session or, when a second factor
+// is required, the pending challenge.
+type LoginResult struct {
+	User      *User
+	Session   *IssuedSession
+	Challenge *MFAChallenge
+}
+
+// AccessDenial is an audit record of an RPC rejected by the authorization interceptor.
+type AccessDenial struct {
+	UserID   *int64
+	Role     string
+	Method   string
+	Reason   string
+	ClientIP string
+}
+
+// RoleChange is one entry of a user's role history. ChangedBy is the acting admin.
+type RoleChange struct {
+	UserID    int64
+	OldRole   string
+	NewRole   string
+	ChangedBy int64
+	Reason    string
+}
+
+type Suspension struct {
+	UserID      int64
+	Reason      string
+	SuspendedBy int64
+	// Until is nil for a ban that has to be lifted manually.
+	Until *time.Time
+}
+
+// ErasureReceipt records that a user's PII was erased, and by whom; it deliberately holds nothing else.
+type ErasureReceipt struct {
+	ID          int64
+	UserID      int64
+	RequestedBy *int64
+	ErasedAt    time.Time
+}
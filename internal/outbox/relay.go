@@ -0,0 +1,122 @@
+package outbox
+
+import (
+	"context"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/store"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/config"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/events"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// Relay moves events from the users_outbox table to the publisher. Delivery is at-least-once:
+// an event stays pending and is retried with exponential backoff until the publisher accepts it.
+type Relay struct {
+	store     *store.Store
+	publisher events.Publisher
+	cfg       config.OutboxConfig
+	logger    *zap.Logger
+
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+}
+
+func NewRelay(store *store.Store, publisher events.Publisher, cfg config.OutboxConfig, logger *zap.Logger) *Relay {
+	return &Relay{
+		store:     store,
+		publisher: publisher,
+		cfg:       cfg,
+		logger:    logger,
+	}
+}
+
+func (r *Relay) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.done.Add(1)
+	go func() {
+		defer r.done.Done()
+		r.run(ctx)
+	}()
+}
+
+// Stop halts polling and waits for the batch in flight to finish.
+func (r *Relay) Stop() {
+	if r.cancel == nil {
+		return
+	}
+
+	r.cancel()
+	r.done.Wait()
+}
+
+func (r *Relay) run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain full batches back to back so a backlog does not wait for the next tick.
+		for {
+			published, err := r.store.ProcessOutbox(ctx, r.cfg.BatchSize, r.cfg.Lease, r.publish, r.backoff)
+			if err != nil {
+				if ctx.Err() == nil {
+					r.logger.Error("users-service | outbox relay failed", zap.Error(err))
+				}
+				break
+			}
+
+			if published < r.cfg.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep deletes events published longer than the retention ago, a batch at a time so no single statement
+// holds locks on a large backlog. It is run periodically alongside the relay.
+func (r *Relay) Sweep(ctx context.Context) error {
+	if r.cfg.Retention <= 0 {
+		return nil
+	}
+
+	publishedBefore := time.Now().Add(-r.cfg.Retention)
+
+	for {
+		deleted, err := r.store.DeletePublishedOutboxEvents(ctx, publishedBefore, r.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		if deleted < int64(r.cfg.BatchSize) {
+			return nil
+		}
+	}
+}
+
+func (r *Relay) publish(ctx context.Context, event *events.Event) error {
+	err := r.publisher.Publish(ctx, event)
+	if err != nil {
+		r.logger.Warn("users-service | failed to publish outbox event",
+			zap.Int64("id", event.ID),
+			zap.String("type", event.Type),
+			zap.Error(err),
+		)
+	}
+	return err
+}
+
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.cfg.RetryBackoff
+	for i := 1; i < attempts && delay < r.cfg.MaxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, r.cfg.MaxRetryBackoff)
+}
@@ -0,0 +1,23 @@
+package outbox
+
+import (
+	"context"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/store"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/events"
+)
+
+var _ events.Publisher = (*Writer)(nil)
+
+// Writer is the Publisher handed to components that emit events outside of a users mutation;
+// it enqueues them in the outbox so they share the relay's delivery guarantees.
+type Writer struct {
+	store *store.Store
+}
+
+func NewWriter(store *store.Store) *Writer {
+	return &Writer{store: store}
+}
+
+func (w *Writer) Publish(ctx context.Context, event *events.Event) error {
+	return w.store.EnqueueEvent(ctx, event)
+}
@@ -0,0 +1,69 @@
+package pagination
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testCursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        int64     `json:"i"`
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+	want := testCursor{CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), ID: 42}
+
+	token, err := codec.Encode(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got testCursor
+	if err = codec.Decode(token, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !got.CreatedAt.Equal(want.CreatedAt) || got.ID != want.ID {
+		t.Errorf("Decode = %+v, want %+v", got, want)
+	}
+}
+
+func TestCodecDecodeInvalid(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+
+	token, err := codec.Encode(testCursor{ID: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload, signature, _ := strings.Cut(token, ".")
+	forged, _ := NewCodec([]byte("other")).Encode(testCursor{ID: 1})
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+	notJSON, _ := codec.Encode("string, not a cursor")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "empty", token: ""},
+		{name: "no signature", token: payload},
+		{name: "other secret", token: forged},
+		{name: "swapped payload", token: forgedPayload + "." + signature},
+		{name: "truncated signature", token: payload + "." + signature[:len(signature)-2]},
+		{name: "bad payload encoding", token: "!!." + signature},
+		{name: "bad signature encoding", token: payload + ".!!"},
+		{name: "wrong payload type", token: notJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cursor testCursor
+			if err := codec.Decode(tt.token, &cursor); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Decode(%q) = %v, want ErrInvalidToken", tt.token, err)
+			}
+		})
+	}
+}
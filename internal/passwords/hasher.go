@@ -0,0 +1,82 @@
+package passwords
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Algorithms accepted by NewHasher for new hashes.
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+var (
+	ErrMismatch         = errors.New("password does not match")
+	ErrUnknownAlgorithm = errors.New("unknown password hash algorithm")
+	ErrMalformedHash    = errors.New("malformed password hash")
+)
+
+type algorithm interface {
+	// recognizes reports whether hash is in this algorithm's format.
+	recognizes(hash string) bool
+	hash(password string) (string, error)
+	verify(hash, password string) error
+	// current reports whether hash was made with exactly the configured parameters.
+	current(hash string) bool
+}
+
+// Hasher hashes new passwords with one configured algorithm and verifies hashes made by any supported
+// one. Stored hashes are self-describing (modular crypt / PHC strings), so mixed algorithms can coexist
+// and old hashes are upgraded as users log in.
+type Hasher struct {
+	current    algorithm
+	algorithms []algorithm
+}
+
+func NewHasher(name string, bcryptCost int, argon2 Argon2Params) (*Hasher, error) {
+	bcryptAlg := &bcryptAlgorithm{cost: bcryptCost}
+	argon2Alg := &argon2Algorithm{params: argon2}
+
+	h := &Hasher{algorithms: []algorithm{argon2Alg, bcryptAlg}}
+
+	switch name {
+	case AlgorithmArgon2id:
+		h.current = argon2Alg
+	case AlgorithmBcrypt:
+		h.current = bcryptAlg
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAlgorithm, name)
+	}
+
+	return h, nil
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.current.hash(password)
+}
+
+// Verify returns nil when password matches hash and ErrMismatch when it does not.
+func (h *Hasher) Verify(hash, password string) error {
+	alg, err := h.algorithmOf(hash)
+	if err != nil {
+		return err
+	}
+
+	return alg.verify(hash, password)
+}
+
+// NeedsRehash reports whether hash should be replaced with one from the current algorithm and parameters.
+func (h *Hasher) NeedsRehash(hash string) bool {
+	return !h.current.recognizes(hash) || !h.current.current(hash)
+}
+
+func (h *Hasher) algorithmOf(hash string) (algorithm, error) {
+	for _, alg := range h.algorithms {
+		if alg.recognizes(hash) {
+			return alg, nil
+		}
+	}
+
+	return nil, ErrUnknownAlgorithm
+}
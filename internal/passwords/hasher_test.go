@@ -0,0 +1,101 @@
+package passwords
+
+import (
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+	"testing"
+)
+
+var testArgon2 = Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestHasherRoundTrip(t *testing.T) {
+	for _, name := range []string{AlgorithmBcrypt, AlgorithmArgon2id} {
+		t.Run(name, func(t *testing.T) {
+			hasher, err := NewHasher(name, bcrypt.MinCost, testArgon2)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			hash, err := hasher.Hash("correct horse")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err = hasher.Verify(hash, "correct horse"); err != nil {
+				t.Errorf("Verify(matching) = %v, want nil", err)
+			}
+
+			if err = hasher.Verify(hash, "wrong horse"); !errors.Is(err, ErrMismatch) {
+				t.Errorf("Verify(mismatching) = %v, want ErrMismatch", err)
+			}
+
+			if hasher.NeedsRehash(hash) {
+				t.Error("NeedsRehash of a fresh hash = true, want false")
+			}
+		})
+	}
+}
+
+func TestHasherNeedsRehash(t *testing.T) {
+	oldBcrypt, _ := NewHasher(AlgorithmBcrypt, bcrypt.MinCost, testArgon2)
+	oldArgon2, _ := NewHasher(AlgorithmArgon2id, bcrypt.MinCost, Argon2Params{Memory: 512, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	current, _ := NewHasher(AlgorithmArgon2id, bcrypt.MinCost, testArgon2)
+
+	tests := []struct {
+		name   string
+		hasher *Hasher
+		want   bool
+	}{
+		{name: "other algorithm", hasher: oldBcrypt, want: true},
+		{name: "other parameters", hasher: oldArgon2, want: true},
+		{name: "current", hasher: current, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := tt.hasher.Hash("correct horse")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := current.NeedsRehash(hash); got != tt.want {
+				t.Errorf("NeedsRehash = %v, want %v", got, tt.want)
+			}
+
+			if err = current.Verify(hash, "correct horse"); err != nil {
+				t.Errorf("Verify = %v, want hashes of every algorithm accepted", err)
+			}
+		})
+	}
+}
+
+func TestHasherVerifyMalformed(t *testing.T) {
+	hasher, _ := NewHasher(AlgorithmArgon2id, bcrypt.MinCost, testArgon2)
+
+	tests := []struct {
+		name string
+		hash string
+		want error
+	}{
+		{name: "unknown algorithm", hash: "$1$abc$def", want: ErrUnknownAlgorithm},
+		{name: "missing parts", hash: "$argon2id$v=19$m=1024,t=1,p=1", want: ErrMalformedHash},
+		{name: "wrong version", hash: "$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$a2V5", want: ErrMalformedHash},
+		{name: "bad parameters", hash: "$argon2id$v=19$m=x$c2FsdA$a2V5", want: ErrMalformedHash},
+		{name: "bad salt", hash: "$argon2id$v=19$m=1024,t=1,p=1$!!$a2V5", want: ErrMalformedHash},
+		{name: "empty key", hash: "$argon2id$v=19$m=1024,t=1,p=1$c2FsdA$", want: ErrMalformedHash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := hasher.Verify(tt.hash, "correct horse"); !errors.Is(err, tt.want) {
+				t.Errorf("Verify(%q) = %v, want %v", tt.hash, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewHasherUnknownAlgorithm(t *testing.T) {
+	if _, err := NewHasher("md5", bcrypt.MinCost, testArgon2); !errors.Is(err, ErrUnknownAlgorithm) {
+		t.Errorf("NewHasher(md5) = %v, want ErrUnknownAlgorithm", err)
+	}
+}
@@ -0,0 +1,223 @@
+package passwords
+
+import (
+	"bufio"
+	_ "embed"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rules reported in Violation.Rule; clients can match on them to render their own messages.
+const (
+	RuleTooShort         = "PASSWORD_TOO_SHORT"
+	RuleTooLong          = "PASSWORD_TOO_LONG"
+	RuleCharacterClasses = "PASSWORD_CHARACTER_CLASSES"
+	RuleCommon           = "PASSWORD_COMMON"
+	RuleSimilarToAccount = "PASSWORD_SIMILAR_TO_ACCOUNT"
+	RuleGuessable        = "PASSWORD_GUESSABLE"
+	RuleReused           = "PASSWORD_REUSED"
+	RuleBreached         = "PASSWORD_BREACHED"
+)
+
+// minAccountTokenLength keeps short name parts such as "Li" from rejecting half the passwords.
+const minAccountTokenLength = 3
+
+//go:embed common.txt
+var commonList string
+
+type Violation struct {
+	Rule    string
+	Message string
+}
+
+type PolicyParams struct {
+	MinLength int
+	MaxLength int
+	// MinCharacterClasses is how many of lowercase, uppercase, digits and symbols must appear.
+	MinCharacterClasses int
+	MinEntropyBits      float64
+}
+
+// Policy checks candidate passwords against length, composition, a denylist of common passwords,
+// similarity to the account's own data and an entropy estimate.
+type Policy struct {
+	params PolicyParams
+	common map[string]struct{}
+}
+
+func NewPolicy(params PolicyParams) *Policy {
+	common := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(strings.NewReader(commonList))
+	for scanner.Scan() {
+		if word := strings.TrimSpace(scanner.Text()); word != "" {
+			common[normalize(word)] = struct{}{}
+		}
+	}
+
+	return &Policy{params: params, common: common}
+}
+
+// Check returns every rule password violates. account holds the user's email, name and slug.
+func (p *Policy) Check(password string, account ...string) []Violation {
+	var violations []Violation
+
+	length := utf8.RuneCountInString(password)
+
+	if length < p.params.MinLength {
+		violations = append(violations, Violation{Rule: RuleTooShort, Message: "password is too short"})
+	}
+
+	if p.params.MaxLength > 0 && length > p.params.MaxLength {
+		violations = append(violations, Violation{Rule: RuleTooLong, Message: "password is too long"})
+	}
+
+	if characterClasses(password) < p.params.MinCharacterClasses {
+		violations = append(violations, Violation{Rule: RuleCharacterClasses, Message: "password must mix lowercase and uppercase letters, digits and symbols"})
+	}
+
+	normalized := normalize(password)
+
+	if _, ok := p.common[normalized]; ok {
+		violations = append(violations, Violation{Rule: RuleCommon, Message: "password is too common"})
+	}
+
+	if similarToAccount(normalized, account) {
+		violations = append(violations, Violation{Rule: RuleSimilarToAccount, Message: "password must not contain your email or name"})
+	}
+
+	if p.EntropyBits(password) < p.params.MinEntropyBits {
+		violations = append(violations, Violation{Rule: RuleGuessable, Message: "password is too easy to guess"})
+	}
+
+	return violations
+}
+
+// EntropyBits is a rough zxcvbn-style guessability estimate: each character is worth log2 of the
+// character pool it was drawn from, except that repeats and runs such as "aaa" or "1234" are worth
+// one bit, and an embedded common password is worth only the bits needed to pick it from the list.
+func (p *Policy) EntropyBits(password string) float64 {
+	runes := []rune(password)
+	perChar := math.Log2(float64(poolSize(password)))
+	normalized := []rune(normalize(password))
+	if len(normalized) != len(runes) {
+		normalized = runes
+	}
+
+	bits := 0.0
+	for i := 0; i < len(runes); {
+		if n := p.commonPrefix(normalized[i:]); n > 0 {
+			bits += math.Log2(float64(len(p.common)))
+			i += n
+			continue
+		}
+
+		if i > 0 && isPattern(runes[i-1], runes[i]) {
+			bits++
+		} else {
+			bits += perChar
+		}
+		i++
+	}
+
+	return bits
+}
+
+// commonPrefix returns the length of the longest common password, of at least four characters,
+// that s starts with.
+func (p *Policy) commonPrefix(s []rune) int {
+	for n := len(s); n >= 4; n-- {
+		if _, ok := p.common[string(s[:n])]; ok {
+			return n
+		}
+	}
+
+	return 0
+}
+
+func isPattern(prev, cur rune) bool {
+	return cur == prev || cur == prev+1 || cur == prev-1
+}
+
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+
+	return lower + upper + digit + symbol
+}
+
+func poolSize(password string) int {
+	pool := 0
+	var lower, upper, digit, symbol, other bool
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < utf8.RuneSelf:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if class.present {
+			pool += class.size
+		}
+	}
+
+	return max(pool, 1)
+}
+
+func similarToAccount(normalized string, account []string) bool {
+	for _, value := range account {
+		for _, token := range accountTokens(value) {
+			if strings.Contains(normalized, token) || (len(normalized) >= minAccountTokenLength && strings.Contains(token, normalized)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// accountTokens splits an email, name or slug into the parts a user might reuse in a password.
+func accountTokens(value string) []string {
+	value, _, _ = strings.Cut(value, "@")
+
+	var tokens []string
+	for _, token := range strings.FieldsFunc(normalize(value), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		if utf8.RuneCountInString(token) >= minAccountTokenLength {
+			tokens = append(tokens, token)
+		}
+	}
+
+	return tokens
+}
+
+var leetReplacer = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s")
+
+// normalize lowercases s and undoes common character substitutions, so "P@ssw0rd" matches "password".
+func normalize(s string) string {
+	return leetReplacer.Replace(strings.ToLower(s))
+}
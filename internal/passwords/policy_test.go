@@ -0,0 +1,66 @@
+package passwords
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	policy := NewPolicy(PolicyParams{MinLength: 10, MaxLength: 64, MinCharacterClasses: 2, MinEntropyBits: 45})
+
+	tests := []struct {
+		name     string
+		password string
+		account  []string
+		want     []string
+	}{
+		{name: "strong", password: "tangerine-Velvet-87-orbit", want: nil},
+		{name: "too short", password: "Xq7#", want: []string{RuleTooShort, RuleGuessable}},
+		{name: "too long", password: "Aa1-" + strings.Repeat("x", 61), want: []string{RuleTooLong}},
+		{name: "single class", password: "qwmzrtvbnxpl", want: []string{RuleCharacterClasses}},
+		{name: "common", password: "password123", want: []string{RuleCommon, RuleGuessable}},
+		{name: "common with substitutions", password: "P@ssw0rd123", want: []string{RuleCommon, RuleGuessable}},
+		{name: "contains email", password: "jdoe-Velvet-87-orbit", account: []string{"jdoe@example.com"}, want: []string{RuleSimilarToAccount}},
+		{name: "contains name", password: "Velvet-87-Martinez", account: []string{"", "Ana Martinez"}, want: []string{RuleSimilarToAccount}},
+		{name: "short name part ignored", password: "tangerine-Velvet-87-li", account: []string{"", "Li Wu"}, want: nil},
+		{name: "sequence", password: "abcdefgh12345678", want: []string{RuleGuessable}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, violation := range policy.Check(tt.password, tt.account...) {
+				got = append(got, violation.Rule)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Check(%q) = %v, want %v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntropyBits(t *testing.T) {
+	policy := NewPolicy(PolicyParams{})
+
+	tests := []struct {
+		name     string
+		weaker   string
+		stronger string
+	}{
+		{name: "repeats", weaker: "aaaaaaaa", stronger: "aqzmxwpt"},
+		{name: "runs", weaker: "12345678", stronger: "19283746"},
+		{name: "embedded common password", weaker: "xpassword", stronger: "xqmzvbrtk"},
+		{name: "more classes", weaker: "qmzvbrtk", stronger: "qMz7bR-k"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weaker, stronger := policy.EntropyBits(tt.weaker), policy.EntropyBits(tt.stronger)
+			if weaker >= stronger {
+				t.Errorf("EntropyBits(%q) = %.1f, want below EntropyBits(%q) = %.1f", tt.weaker, weaker, tt.stronger, stronger)
+			}
+		})
+	}
+}
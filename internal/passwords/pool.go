@@ -0,0 +1,127 @@
+package passwords
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrSaturated is returned when every worker is busy and the queue is full.
+	ErrSaturated   = errors.New("password hashing queue is full")
+	ErrPoolStopped = errors.New("password hashing pool is stopped")
+)
+
+// Pool runs password hashing on a fixed number of workers, so a burst of logins or a long password
+// history cannot occupy every CPU. Work beyond the workers waits in a bounded queue; past that it is
+// rejected straight away rather than piling up.
+type Pool struct {
+	queue       chan *poolJob
+	stop        chan struct{}
+	observeWait func(time.Duration)
+
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+type poolJob struct {
+	ctx      context.Context
+	fn       func()
+	enqueued time.Time
+	done     chan struct{}
+	// state moves from jobQueued to either jobRunning, claimed by a worker, or jobAbandoned, by Do giving up.
+	state atomic.Int32
+}
+
+const (
+	jobQueued int32 = iota
+	jobRunning
+	jobAbandoned
+)
+
+// NewPool starts workers goroutines, GOMAXPROCS when workers is 0. observeWait is called with the
+// time each job spent queued.
+func NewPool(workers, queueSize int, observeWait func(time.Duration)) *Pool {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	p := &Pool{
+		queue:       make(chan *poolJob, max(queueSize, 0)),
+		stop:        make(chan struct{}),
+		observeWait: observeWait,
+	}
+
+	p.wg.Add(workers)
+	for range workers {
+		go p.work()
+	}
+
+	return p
+}
+
+// Do runs fn on a worker and waits for it. It returns ErrSaturated without queueing when the queue is
+// full, and ctx.Err() once ctx is done; fn is skipped if that happens while it is still queued, but
+// one already running cannot be interrupted and is waited for, so fn never runs after Do returns.
+func (p *Pool) Do(ctx context.Context, fn func()) error {
+	job := &poolJob{ctx: ctx, fn: fn, enqueued: time.Now(), done: make(chan struct{})}
+
+	select {
+	case <-p.stop:
+		return ErrPoolStopped
+	default:
+	}
+
+	select {
+	case p.queue <- job:
+	default:
+		return ErrSaturated
+	}
+
+	select {
+	case <-job.done:
+		return nil
+	case <-ctx.Done():
+		return job.abandon(ctx.Err())
+	case <-p.stop:
+		return job.abandon(ErrPoolStopped)
+	}
+}
+
+// abandon keeps a queued job from running and returns err, first waiting for the job if a worker already runs it.
+func (j *poolJob) abandon(err error) error {
+	if !j.state.CompareAndSwap(jobQueued, jobAbandoned) {
+		<-j.done
+	}
+
+	return err
+}
+
+// Stop lets running jobs finish and abandons queued ones.
+func (p *Pool) Stop() {
+	p.stopOnce.Do(func() { close(p.stop) })
+	p.wg.Wait()
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case job := <-p.queue:
+			if job.ctx.Err() != nil || !job.state.CompareAndSwap(jobQueued, jobRunning) {
+				continue
+			}
+
+			p.observeWait(time.Since(job.enqueued))
+
+			job.fn()
+			close(job.done)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package passwords
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPoolDoWaitsForRunningJobOnCancel(t *testing.T) {
+	pool := NewPool(1, 1, func(time.Duration) {})
+	defer pool.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	returned := make(chan error, 1)
+
+	var result string
+
+	go func() {
+		returned <- pool.Do(ctx, func() {
+			close(started)
+			<-release
+			result = "hashed"
+		})
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case err := <-returned:
+		close(release)
+		t.Fatalf("Do returned %v while its job was still running", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+
+	if err := <-returned; !errors.Is(err, context.Canceled) {
+		t.Errorf("Do = %v, want context.Canceled", err)
+	}
+
+	// Run with -race: the job's write must happen before Do returns.
+	if result != "hashed" {
+		t.Errorf("result = %q, want the job's write to be visible", result)
+	}
+}
+
+func TestPoolDoSkipsQueuedJobOnCancel(t *testing.T) {
+	pool := NewPool(1, 1, func(time.Duration) {})
+	defer pool.Stop()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	busy := make(chan error, 1)
+
+	go func() {
+		busy <- pool.Do(context.Background(), func() {
+			close(started)
+			<-release
+		})
+	}()
+
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	ran := false
+	if err := pool.Do(ctx, func() { ran = true }); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do of a queued job = %v, want context.DeadlineExceeded", err)
+	}
+
+	close(release)
+
+	if err := <-busy; err != nil {
+		t.Fatal(err)
+	}
+
+	// Once the worker is free again it must skip the abandoned job.
+	if err := pool.Do(context.Background(), func() {}); err != nil {
+		t.Fatal(err)
+	}
+
+	if ran {
+		t.Error("abandoned job ran")
+	}
+}
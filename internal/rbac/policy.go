@@ -0,0 +1,78 @@
+package rbac
+
+import users "github.com/Brain-Wave-Ecosystem/users-service/gen/users"
+
+// Requirement is what a caller needs to invoke an RPC.
+type Requirement struct {
+	// Authenticated requires a forwarded user id.
+	Authenticated bool
+	// Permission, when set, also requires the caller's role to grant it.
+	Permission Permission
+}
+
+var (
+	public        = Requirement{}
+	authenticated = Requirement{Authenticated: true}
+)
+
+func requires(permission Permission) Requirement {
+	return Requirement{Authenticated: true, Permission: permission}
+}
+
+// Policy declares the requirement of every UsersService RPC. Methods missing from it are denied,
+// so a new RPC stays closed until someone decides who may call it.
+var Policy = map[string]Requirement{
+	users.UsersService_GetUserByIdentifier_FullMethodName:      public,
+	users.UsersService_SearchUsers_FullMethodName:              public,
+	users.UsersService_CreateUser_FullMethodName:               public,
+	users.UsersService_LoginUserByEmail_FullMethodName:         public,
+	users.UsersService_VerifyMfaLogin_FullMethodName:           public,
+	users.UsersService_RequestEmailVerification_FullMethodName: public,
+	users.UsersService_VerifyEmail_FullMethodName:              public,
+	users.UsersService_RequestPasswordReset_FullMethodName:     public,
+	users.UsersService_ResetPassword_FullMethodName:            public,
+	users.UsersService_RefreshSession_FullMethodName:           public,
+
+	users.UsersService_GetUserProfile_FullMethodName:             authenticated,
+	users.UsersService_UpdateUser_FullMethodName:                 authenticated,
+	users.UsersService_UpdateUserPassword_FullMethodName:         authenticated,
+	users.UsersService_DeleteUser_FullMethodName:                 authenticated,
+	users.UsersService_EnrollMfa_FullMethodName:                  authenticated,
+	users.UsersService_ConfirmMfaEnrollment_FullMethodName:       authenticated,
+	users.UsersService_RegenerateMfaRecoveryCodes_FullMethodName: authenticated,
+	users.UsersService_DisableMfa_FullMethodName:                 authenticated,
+	users.UsersService_RestoreUser_FullMethodName:                authenticated,
+	users.UsersService_ExportUserData_FullMethodName:             authenticated,
+	users.UsersService_EraseUser_FullMethodName:                  authenticated,
+	users.UsersService_ListSessions_FullMethodName:               authenticated,
+	users.UsersService_RevokeSession_FullMethodName:              authenticated,
+	users.UsersService_RevokeAllSessions_FullMethodName:          authenticated,
+
+	users.UsersService_ListUsers_FullMethodName:               requires(PermUsersList),
+	users.UsersService_ConfirmUser_FullMethodName:             requires(PermUsersConfirm),
+	users.UsersService_UpdateUserAdmin_FullMethodName:         requires(PermUsersUpdateProfile),
+	users.UsersService_UpdateUserPasswordAdmin_FullMethodName: requires(PermUsersUpdatePassword),
+	users.UsersService_DeleteUserAdmin_FullMethodName:         requires(PermUsersDelete),
+	users.UsersService_ChangeUserRole_FullMethodName:          requires(PermUsersChangeRole),
+	users.UsersService_SuspendUser_FullMethodName:             requires(PermUsersSuspend),
+	users.UsersService_UnsuspendUser_FullMethodName:           requires(PermUsersSuspend),
+	users.UsersService_ExportUserDataAdmin_FullMethodName:     requires(PermUsersExport),
+	users.UsersService_GetLoginLockout_FullMethodName:         requires(PermLockoutsRead),
+	users.UsersService_ClearLoginLockout_FullMethodName:       requires(PermLockoutsManage),
+}
+
+// Authorize reports whether caller satisfies the requirement of method and, if not, why.
+func Authorize(method string, caller Caller) (ok bool, reason string) {
+	requirement, known := Policy[method]
+
+	switch {
+	case !known:
+		return false, "method has no access policy"
+	case requirement.Authenticated && !caller.Authenticated():
+		return false, "authentication required"
+	case requirement.Permission != "" && !caller.Role.Can(requirement.Permission):
+		return false, "role " + string(caller.Role) + " lacks " + string(requirement.Permission)
+	}
+
+	return true, ""
+}
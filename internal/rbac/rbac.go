@@ -0,0 +1,149 @@
+package rbac
+
+import (
+	"context"
+	"google.golang.org/grpc/metadata"
+	"slices"
+	"strconv"
+)
+
+// Metadata keys set by the API gateway after it has authenticated the caller.
+const (
+	UserIDKey   = "user-id"
+	UserRoleKey = "user-role"
+)
+
+// Role mirrors the role enum in Postgres.
+type Role string
+
+const (
+	RoleAdmin      Role = "admin"
+	RoleModerator  Role = "moderator"
+	RoleInstructor Role = "instructor"
+	RoleStudent    Role = "student"
+	RoleUser       Role = "user"
+	RoleGuest      Role = "guest"
+)
+
+type Permission string
+
+const (
+	PermUsersList           Permission = "users:list"
+	PermUsersConfirm        Permission = "users:confirm"
+	PermUsersUpdateProfile  Permission = "users:update-profile"
+	PermUsersUpdatePassword Permission = "users:update-password"
+	PermUsersDelete         Permission = "users:delete"
+	PermUsersChangeRole     Permission = "users:change-role"
+	PermUsersSuspend        Permission = "users:suspend"
+	PermUsersRestore        Permission = "users:restore"
+	PermUsersExport         Permission = "users:export"
+	PermUsersErase          Permission = "users:erase"
+	PermLockoutsRead        Permission = "lockouts:read"
+	PermLockoutsManage      Permission = "lockouts:manage"
+)
+
+// rolePermissions lists what each role may do to accounts other than the caller's own.
+// Every authenticated caller can manage their own account regardless of role.
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermUsersList,
+		PermUsersConfirm,
+		PermUsersUpdateProfile,
+		PermUsersUpdatePassword,
+		PermUsersDelete,
+		PermUsersChangeRole,
+		PermUsersSuspend,
+		PermUsersRestore,
+		PermUsersExport,
+		PermUsersErase,
+		PermLockoutsRead,
+		PermLockoutsManage,
+	},
+	RoleModerator: {
+		PermUsersList,
+		PermUsersUpdateProfile,
+		PermUsersSuspend,
+		PermLockoutsRead,
+	},
+	RoleInstructor: {},
+	RoleStudent:    {},
+	RoleUser:       {},
+	RoleGuest:      {},
+}
+
+// roleRanks orders the roles for actions on other accounts: a caller may only act on users ranked below them.
+var roleRanks = map[Role]int{
+	RoleAdmin:      5,
+	RoleModerator:  4,
+	RoleInstructor: 3,
+	RoleStudent:    2,
+	RoleUser:       1,
+	RoleGuest:      0,
+}
+
+func ParseRole(s string) (Role, bool) {
+	role := Role(s)
+	_, ok := rolePermissions[role]
+	return role, ok
+}
+
+func (r Role) Can(permission Permission) bool {
+	return slices.Contains(rolePermissions[r], permission)
+}
+
+// Outranks reports whether r ranks strictly above other.
+func (r Role) Outranks(other Role) bool {
+	return roleRanks[r] > roleRanks[other]
+}
+
+// CanManage reports whether r may act on an account holding target. Admins may also act on each other,
+// otherwise no admin could ever be demoted.
+func (r Role) CanManage(target Role) bool {
+	return r == RoleAdmin || r.Outranks(target)
+}
+
+// Caller is the identity the gateway forwarded with the request. UserID is zero for anonymous calls.
+type Caller struct {
+	UserID int64
+	Role   Role
+}
+
+func (c Caller) Authenticated() bool {
+	return c.UserID != 0
+}
+
+// CallerFromMD reads the caller from incoming metadata. A missing or unknown role degrades to guest,
+// so a malformed header can never grant more than the lowest role.
+func CallerFromMD(ctx context.Context) Caller {
+	caller := Caller{Role: RoleGuest}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return caller
+	}
+
+	if values := md.Get(UserIDKey); len(values) > 0 {
+		if id, err := strconv.ParseInt(values[0], 10, 64); err == nil && id > 0 {
+			caller.UserID = id
+		}
+	}
+
+	if values := md.Get(UserRoleKey); len(values) > 0 && caller.Authenticated() {
+		if role, ok := ParseRole(values[0]); ok {
+			caller.Role = role
+		}
+	}
+
+	return caller
+}
+
+type callerKey struct{}
+
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+func CallerFromContext(ctx context.Context) (Caller, bool) {
+	caller, ok := ctx.Value(callerKey{}).(Caller)
+	return caller, ok
+}
@@ -0,0 +1,135 @@
+package rbac
+
+import (
+	"context"
+	users "github.com/Brain-Wave-Ecosystem/users-service/gen/users"
+	"google.golang.org/grpc/metadata"
+	"testing"
+)
+
+func TestCallerFromMD(t *testing.T) {
+	tests := []struct {
+		name string
+		md   metadata.MD
+		want Caller
+	}{
+		{name: "no metadata", md: nil, want: Caller{Role: RoleGuest}},
+		{name: "anonymous", md: metadata.Pairs(), want: Caller{Role: RoleGuest}},
+		{name: "user", md: metadata.Pairs(UserIDKey, "7", UserRoleKey, "moderator"), want: Caller{UserID: 7, Role: RoleModerator}},
+		{name: "missing role", md: metadata.Pairs(UserIDKey, "7"), want: Caller{UserID: 7, Role: RoleGuest}},
+		{name: "unknown role", md: metadata.Pairs(UserIDKey, "7", UserRoleKey, "root"), want: Caller{UserID: 7, Role: RoleGuest}},
+		{name: "role without user", md: metadata.Pairs(UserRoleKey, "admin"), want: Caller{Role: RoleGuest}},
+		{name: "malformed id", md: metadata.Pairs(UserIDKey, "7x", UserRoleKey, "admin"), want: Caller{Role: RoleGuest}},
+		{name: "negative id", md: metadata.Pairs(UserIDKey, "-7", UserRoleKey, "admin"), want: Caller{Role: RoleGuest}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, tt.md)
+			}
+
+			if got := CallerFromMD(ctx); got != tt.want {
+				t.Errorf("CallerFromMD = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	guest := Caller{Role: RoleGuest}
+	user := Caller{UserID: 1, Role: RoleUser}
+	moderator := Caller{UserID: 2, Role: RoleModerator}
+	admin := Caller{UserID: 3, Role: RoleAdmin}
+
+	tests := []struct {
+		name   string
+		method string
+		caller Caller
+		want   bool
+	}{
+		{name: "public", method: users.UsersService_CreateUser_FullMethodName, caller: guest, want: true},
+		{name: "authenticated as guest", method: users.UsersService_GetUserProfile_FullMethodName, caller: guest, want: false},
+		{name: "authenticated as user", method: users.UsersService_GetUserProfile_FullMethodName, caller: user, want: true},
+		{name: "permission missing", method: users.UsersService_ListUsers_FullMethodName, caller: user, want: false},
+		{name: "permission granted", method: users.UsersService_ListUsers_FullMethodName, caller: moderator, want: true},
+		{name: "admin only as moderator", method: users.UsersService_ChangeUserRole_FullMethodName, caller: moderator, want: false},
+		{name: "admin only as admin", method: users.UsersService_ChangeUserRole_FullMethodName, caller: admin, want: true},
+		{name: "unknown method", method: "/users.UsersService/DropDatabase", caller: admin, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, reason := Authorize(tt.method, tt.caller)
+			if ok != tt.want {
+				t.Errorf("Authorize(%s, %+v) = %v (%s), want %v", tt.method, tt.caller, ok, reason, tt.want)
+			}
+
+			if !ok && reason == "" {
+				t.Error("denied without a reason")
+			}
+		})
+	}
+}
+
+func TestPolicyCoversEveryMethod(t *testing.T) {
+	for _, method := range users.UsersService_ServiceDesc.Methods {
+		name := "/" + users.UsersService_ServiceDesc.ServiceName + "/" + method.MethodName
+		if _, ok := Policy[name]; !ok {
+			t.Errorf("%s has no access policy", name)
+		}
+	}
+}
+
+func TestOutranks(t *testing.T) {
+	tests := []struct {
+		role  Role
+		other Role
+		want  bool
+	}{
+		{role: RoleAdmin, other: RoleModerator, want: true},
+		{role: RoleAdmin, other: RoleAdmin, want: false},
+		{role: RoleModerator, other: RoleModerator, want: false},
+		{role: RoleModerator, other: RoleInstructor, want: true},
+		{role: RoleModerator, other: RoleAdmin, want: false},
+		{role: RoleStudent, other: RoleUser, want: true},
+		{role: RoleUser, other: RoleGuest, want: true},
+		{role: RoleGuest, other: RoleGuest, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.role.Outranks(tt.other); got != tt.want {
+			t.Errorf("%s.Outranks(%s) = %v, want %v", tt.role, tt.other, got, tt.want)
+		}
+	}
+}
+
+func TestCanManage(t *testing.T) {
+	tests := []struct {
+		role   Role
+		target Role
+		want   bool
+	}{
+		{role: RoleAdmin, target: RoleAdmin, want: true},
+		{role: RoleAdmin, target: RoleUser, want: true},
+		{role: RoleModerator, target: RoleAdmin, want: false},
+		{role: RoleModerator, target: RoleModerator, want: false},
+		{role: RoleModerator, target: RoleStudent, want: true},
+		{role: RoleGuest, target: RoleGuest, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.role.CanManage(tt.target); got != tt.want {
+			t.Errorf("%s.CanManage(%s) = %v, want %v", tt.role, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestEveryRoleIsRanked(t *testing.T) {
+	for role := range rolePermissions {
+		if _, ok := roleRanks[role]; !ok {
+			t.Errorf("role %s has no rank", role)
+		}
+	}
+}
@@ -2,32 +2,70 @@ package server
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"github.com/Brain-Wave-Ecosystem/go-common/pkg/abstractions"
 	"github.com/Brain-Wave-Ecosystem/go-common/pkg/clients"
 	"github.com/Brain-Wave-Ecosystem/go-common/pkg/consul"
 	"github.com/Brain-Wave-Ecosystem/go-common/pkg/log"
 	users "github.com/Brain-Wave-Ecosystem/users-service/gen/users"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/blob"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/cache"
 	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/handler"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/lockout"
 	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/service"
 	"github.com/Brain-Wave-Ecosystem/users-service/internal/apis/store"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/audit"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/breach"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/clientip"
 	"github.com/Brain-Wave-Ecosystem/users-service/internal/config"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/docs"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/events"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/gateway"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/health"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/interceptors"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/jobs"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/metrics"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/mfa"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/notifications"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/outbox"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/pagination"
+	"github.com/Brain-Wave-Ecosystem/users-service/internal/passwords"
 	"github.com/DavidMovas/gopherbox/pkg/closer"
+	"github.com/bufbuild/protovalidate-go"
+	"github.com/nats-io/nats.go"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/credentials/insecure"
+	grpchealth "google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"net"
+	"net/http"
+	"time"
 )
 
 var _ abstractions.Server = (*Server)(nil)
 
 type Server struct {
 	grpcServer *grpc.Server
+	httpServer *http.Server
+	opsServer  *http.Server
+	monitor    *health.Monitor
 	consul     *consul.Consul
 	logger     *log.Logger
 	cfg        *config.Config
 	closer     *closer.Closer
+	// workers run in the background from Start until the closer stops them.
+	workers []worker
+}
+
+type worker interface {
+	Start()
+	Stop()
 }
 
 func NewServer(ctx context.Context, cfg *config.Config) (*Server, error) {
@@ -48,13 +86,11 @@ func NewServer(ctx context.Context, cfg *config.Config) (*Server, error) {
 
 	cl.Push(consulManager.Stop)
 
-	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor())
-
-	healthServer := health.NewServer()
-	healthServer.SetServingStatus(fmt.Sprintf("%s-%d", cfg.Name, cfg.GRPCPort), grpc_health_v1.HealthCheckResponse_SERVING)
-	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
-
-	cl.PushNE(healthServer.Shutdown)
+	validator, err := protovalidate.New()
+	if err != nil {
+		logger.Zap().Error("error initializing validator", zap.Error(err))
+		return nil, fmt.Errorf("error initializing validator: %w", err)
+	}
 
 	postgres, err := clients.NewPostgresClient(ctx, cfg.Postgres.URL, nil)
 	if err != nil {
@@ -65,24 +101,160 @@ func NewServer(ctx context.Context, cfg *config.Config) (*Server, error) {
 	cl.PushNE(postgres.Close)
 
 	s := store.NewStore(postgres)
-	srv := service.NewService(s)
+	serviceMetrics := metrics.New(postgres)
+
+	clientIPs, err := clientip.NewResolver(cfg.Proxy.TrustedCIDRs)
+	if err != nil {
+		logger.Zap().Error("error initializing client ip resolver", zap.Error(err))
+		return nil, fmt.Errorf("error initializing client ip resolver: %w", err)
+	}
+
+	denials := audit.NewDenialQueue(s, cfg.Audit.DenialQueueSize, serviceMetrics.AccessDenialDropped, logger.Zap())
+
+	cl.PushNE(denials.Stop)
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			interceptors.Metrics(serviceMetrics),
+			interceptors.Recovery(logger.Zap()),
+			interceptors.Tracing(cfg.Name),
+			interceptors.Logging(logger.Zap()),
+			interceptors.ClientIP(clientIPs),
+			interceptors.Authorization(denials, logger.Zap()),
+			interceptors.Validation(validator),
+		),
+		grpc.ChainStreamInterceptor(
+			interceptors.MetricsStream(serviceMetrics),
+			interceptors.RecoveryStream(logger.Zap()),
+			interceptors.TracingStream(cfg.Name),
+			interceptors.LoggingStream(logger.Zap()),
+			interceptors.ClientIPStream(clientIPs),
+			interceptors.AuthorizationStream(denials, logger.Zap()),
+			interceptors.ValidationStream(validator),
+		),
+	)
+
+	healthServer := grpchealth.NewServer()
+	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
+
+	cl.PushNE(healthServer.Shutdown)
+
+	redisClient, err := newRedisClient(ctx, cfg, logger.Zap(), cl)
+	if err != nil {
+		logger.Zap().Error("error initializing redis client", zap.Error(err))
+		return nil, fmt.Errorf("error initializing redis client: %w", err)
+	}
+
+	monitor := health.NewMonitor(healthServer, []string{"", fmt.Sprintf("%s-%d", cfg.Name, cfg.GRPCPort)}, cfg.Health, logger.Zap())
+	monitor.Register("postgres", postgres.Ping)
+
+	if redisClient != nil {
+		monitor.RegisterOptional("redis", func(ctx context.Context) error { return redisClient.Ping(ctx).Err() })
+	}
+
+	cl.PushNE(monitor.Stop)
+
+	var userCache cache.Cache = cache.NewMemoryCache(cfg.Redis.CacheTTL)
+	var lockoutCounter lockout.Counter = lockout.NewMemoryCounter()
+
+	if redisClient != nil {
+		userCache = cache.NewRedisCache(redisClient, cfg.Redis.CacheTTL)
+		lockoutCounter = lockout.NewFallbackCounter(lockout.NewRedisCounter(redisClient), lockoutCounter, logger.Zap())
+	}
+
+	pageTokens, err := newPageTokenCodec(cfg, logger.Zap())
+	if err != nil {
+		logger.Zap().Error("error initializing page token codec", zap.Error(err))
+		return nil, fmt.Errorf("error initializing page token codec: %w", err)
+	}
+
+	mfaCipher, err := newMFACipher(cfg, logger.Zap())
+	if err != nil {
+		logger.Zap().Error("error initializing mfa cipher", zap.Error(err))
+		return nil, fmt.Errorf("error initializing mfa cipher: %w", err)
+	}
+
+	hasher, err := newPasswordHasher(cfg)
+	if err != nil {
+		logger.Zap().Error("error initializing password hasher", zap.Error(err))
+		return nil, fmt.Errorf("error initializing password hasher: %w", err)
+	}
+
+	hashPool := passwords.NewPool(cfg.PasswordHash.Workers, cfg.PasswordHash.QueueSize, serviceMetrics.ObservePasswordQueueWait)
+
+	cl.PushNE(hashPool.Stop)
+
+	breaches, err := breach.Open(cfg.Breach.Path, cfg.Breach.MinCount)
+	if err != nil {
+		logger.Zap().Error("error opening breached passwords corpus", zap.Error(err))
+		return nil, fmt.Errorf("error opening breached passwords corpus: %w", err)
+	}
+
+	cl.Push(breaches.Close)
+
+	if cfg.Breach.Path == "" {
+		logger.Zap().Warn("BREACH_PATH is not set, breached passwords will not be refused")
+	}
+
+	publisher, err := newEventPublisher(ctx, cfg, logger.Zap(), cl)
+	if err != nil {
+		logger.Zap().Error("error initializing events publisher", zap.Error(err))
+		return nil, fmt.Errorf("error initializing events publisher: %w", err)
+	}
+
+	relay := outbox.NewRelay(s, publisher, cfg.Outbox, logger.Zap())
+
+	cl.PushNE(relay.Stop)
+
+	outboxSweeper := jobs.NewPeriodic("sweep-outbox", cfg.Outbox.SweepInterval, relay.Sweep, logger.Zap())
+
+	cl.PushNE(outboxSweeper.Stop)
+
+	notifier := notifications.NewLogNotifier(logger.Zap())
+
+	blobs, err := blob.NewLocalStore(cfg.Blob.Dir)
+	if err != nil {
+		logger.Zap().Error("error initializing blob store", zap.Error(err))
+		return nil, fmt.Errorf("error initializing blob store: %w", err)
+	}
+	guard := lockout.NewGuard(lockoutCounter, cfg.Lockout, outbox.NewWriter(s), logger.Zap())
+	resends := lockout.NewLimiter(lockoutCounter, "verification", cfg.Verification.ResendCooldown, cfg.Verification.MaxPerDay, 24*time.Hour)
+	srv := service.NewService(s, userCache, pageTokens, notifier, guard, resends, mfaCipher, hasher, hashPool, newPasswordPolicy(cfg), breaches, serviceMetrics, blobs, cfg, logger.Zap())
 	h := handler.NewHandler(srv, logger.Zap())
 
+	suspensionLifter := jobs.NewPeriodic("lift-suspensions", cfg.Suspension.LiftInterval, srv.LiftExpiredSuspensions, logger.Zap())
+
+	cl.PushNE(suspensionLifter.Stop)
+
+	purger := jobs.NewPeriodic("purge-deleted-users", cfg.Purge.Interval, srv.PurgeDeletedUsers, logger.Zap())
+
+	cl.PushNE(purger.Stop)
+
 	users.RegisterUsersServiceServer(grpcServer, h)
 
+	httpServer, err := newHTTPServer(ctx, cfg, h, clientIPs, logger.Zap(), cl)
+	if err != nil {
+		logger.Zap().Error("error initializing http gateway", zap.Error(err))
+		return nil, fmt.Errorf("error initializing http gateway: %w", err)
+	}
+
 	return &Server{
 		grpcServer: grpcServer,
+		httpServer: httpServer,
+		opsServer:  newOpsServer(cfg, monitor, serviceMetrics),
+		monitor:    monitor,
 		consul:     consulManager,
 		logger:     logger,
 		cfg:        cfg,
 		closer:     cl,
+		workers:    []worker{denials, monitor, relay, outboxSweeper, suspensionLifter, purger},
 	}, nil
 }
 
 func (s *Server) Start() error {
 	z := s.logger.Zap()
 
-	z.Info("Starting server", zap.String("name", s.cfg.Name), zap.Int("port", s.cfg.GRPCPort))
+	z.Info("Starting server", zap.String("name", s.cfg.Name), zap.Int("port", s.cfg.GRPCPort), zap.Int("http_port", s.cfg.HTTP.Port), zap.Int("ops_port", s.cfg.Ops.Port), zap.String("version", docs.Version))
 
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.cfg.GRPCPort))
 	if err != nil {
@@ -92,24 +264,252 @@ func (s *Server) Start() error {
 
 	s.closer.PushIO(lis)
 
+	httpLis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.cfg.HTTP.Port))
+	if err != nil {
+		z.Error("Failed to start http listener", zap.String("name", s.cfg.Name), zap.Int("port", s.cfg.HTTP.Port), zap.Error(err))
+		return err
+	}
+
+	opsLis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.cfg.Ops.Port))
+	if err != nil {
+		z.Error("Failed to start ops listener", zap.String("name", s.cfg.Name), zap.Int("port", s.cfg.Ops.Port), zap.Error(err))
+		return err
+	}
+
+	// Workers start only here, so a NewServer that fails half way leaves none of them running.
+	// The monitor has to be up before consul probes it.
+	for _, w := range s.workers {
+		w.Start()
+	}
+
 	err = s.consul.RegisterService()
 	if err != nil {
 		z.Error("Failed to register service in consul registry", zap.String("name", s.cfg.Name), zap.Error(err))
 		return err
 	}
 
-	return s.grpcServer.Serve(lis)
+	var group errgroup.Group
+
+	group.Go(func() error {
+		if err := s.httpServer.Serve(httpLis); !errors.Is(err, http.ErrServerClosed) {
+			s.grpcServer.Stop()
+			return err
+		}
+
+		return nil
+	})
+
+	group.Go(func() error {
+		if err := s.opsServer.Serve(opsLis); !errors.Is(err, http.ErrServerClosed) {
+			s.grpcServer.Stop()
+			return err
+		}
+
+		return nil
+	})
+
+	group.Go(func() error {
+		if err := s.grpcServer.Serve(lis); err != nil {
+			_ = s.httpServer.Close()
+			_ = s.opsServer.Close()
+			return err
+		}
+
+		return nil
+	})
+
+	return group.Wait()
 }
 
+// Shutdown first reports NOT_SERVING and gives load balancers DrainDelay to notice. It then drains the
+// HTTP gateway, whose in-flight requests still need the gRPC server, and stops gRPC gracefully,
+// forcing it once ctx expires.
 func (s *Server) Shutdown(ctx context.Context) error {
 	z := s.logger.Zap()
 
 	z.Info("Shutting down server", zap.String("name", s.cfg.Name))
 
-	s.grpcServer.GracefulStop()
+	s.monitor.Drain()
+
+	select {
+	case <-time.After(s.cfg.Health.DrainDelay):
+	case <-ctx.Done():
+	}
+
+	httpErr := s.httpServer.Shutdown(ctx)
+
+	stopped := make(chan struct{})
 
-	<-ctx.Done()
-	s.grpcServer.Stop()
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
 
-	return s.closer.Close(ctx)
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+	}
+
+	// The probes stay reachable until everything else has drained.
+	opsErr := s.opsServer.Shutdown(ctx)
+
+	return errors.Join(httpErr, opsErr, s.closer.Close(ctx))
+}
+
+// newRedisClient connects to Redis when it is configured and returns nil otherwise, leaving callers on
+// their in-memory implementations. An unreachable Redis at startup is not fatal: dependants degrade until it comes back.
+func newRedisClient(ctx context.Context, cfg *config.Config, logger *zap.Logger, cl *closer.Closer) (*redis.Client, error) {
+	if cfg.Redis.URL == "" {
+		logger.Info("redis url is not set, using in-memory cache and lockout counters")
+		return nil, nil
+	}
+
+	opts, err := redis.ParseURL(cfg.Redis.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.DialTimeout = cfg.Redis.Timeout
+	opts.ReadTimeout = cfg.Redis.Timeout
+	opts.WriteTimeout = cfg.Redis.Timeout
+
+	client := redis.NewClient(opts)
+	cl.PushIO(client)
+
+	if err = client.Ping(ctx).Err(); err != nil {
+		logger.Warn("redis is unavailable, users will be served from postgres", zap.Error(err))
+	}
+
+	return client, nil
+}
+
+// newPageTokenCodec signs page tokens with the configured secret. Without one a random secret is used,
+// which keeps tokens valid only within this instance.
+func newPageTokenCodec(cfg *config.Config, logger *zap.Logger) (*pagination.Codec, error) {
+	if cfg.Pagination.TokenSecret != "" {
+		return pagination.NewCodec([]byte(cfg.Pagination.TokenSecret)), nil
+	}
+
+	logger.Warn("pagination token secret is not set, page tokens will not survive restarts")
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	return pagination.NewCodec(secret), nil
+}
+
+// newMFACipher decodes the base64 AES-256 key protecting TOTP secrets. Only local runs may start
+// without one, using a throwaway key that makes enrollments unreadable after a restart.
+func newMFACipher(cfg *config.Config, logger *zap.Logger) (*mfa.Cipher, error) {
+	if cfg.MFA.EncryptionKey == "" {
+		if !cfg.Local {
+			return nil, errors.New("mfa encryption key is not set")
+		}
+
+		logger.Warn("mfa encryption key is not set, using a temporary key")
+
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+
+		return mfa.NewCipher(key)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(cfg.MFA.EncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode mfa encryption key: %w", err)
+	}
+
+	return mfa.NewCipher(key)
+}
+
+func newPasswordHasher(cfg *config.Config) (*passwords.Hasher, error) {
+	c := cfg.PasswordHash
+
+	return passwords.NewHasher(c.Algorithm, c.BcryptCost, passwords.Argon2Params{
+		Memory:      c.Argon2Memory,
+		Iterations:  c.Argon2Iterations,
+		Parallelism: c.Argon2Parallelism,
+		SaltLength:  c.Argon2SaltLength,
+		KeyLength:   c.Argon2KeyLength,
+	})
+}
+
+func newPasswordPolicy(cfg *config.Config) *passwords.Policy {
+	c := cfg.PasswordPolicy
+
+	return passwords.NewPolicy(passwords.PolicyParams{
+		MinLength:           c.MinLength,
+		MaxLength:           c.MaxLength,
+		MinCharacterClasses: c.MinCharacterClasses,
+		MinEntropyBits:      c.MinEntropyBits,
+	})
+}
+
+// newEventPublisher connects to NATS JetStream when configured; otherwise events are only logged.
+func newEventPublisher(ctx context.Context, cfg *config.Config, logger *zap.Logger, cl *closer.Closer) (events.Publisher, error) {
+	if cfg.NATS.URL == "" {
+		logger.Info("nats url is not set, outbox events will only be logged")
+		return events.NewLogPublisher(logger), nil
+	}
+
+	conn, err := nats.Connect(cfg.NATS.URL, nats.Name(cfg.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	cl.PushNE(conn.Close)
+
+	return events.NewNATSPublisher(ctx, conn, cfg.NATS.SubjectPrefix)
+}
+
+// newHTTPServer serves the REST gateway and its API docs next to the gRPC server. The gateway dials this instance's own
+// gRPC port so REST traffic shares the interceptor chain.
+func newHTTPServer(ctx context.Context, cfg *config.Config, h *handler.Handler, proxies *clientip.Resolver, logger *zap.Logger, cl *closer.Closer) (*http.Server, error) {
+	conn, err := grpc.NewClient(fmt.Sprintf("localhost:%d", cfg.GRPCPort), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+
+	cl.PushIO(conn)
+
+	gatewayMux, err := gateway.NewMux(ctx, conn, proxies, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	docsHandler, err := docs.Handler("/docs")
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", h.Health)
+	mux.Handle("/docs/", docsHandler)
+	mux.Handle("/", gatewayMux)
+
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.HTTP.Port),
+		Handler:           mux,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+	}, nil
+}
+
+// newOpsServer serves the readiness and liveness probes and the Prometheus metrics on the ops port,
+// which is meant for the orchestrator and the scraper only: the readiness report names failing dependencies.
+func newOpsServer(cfg *config.Config, monitor *health.Monitor, serviceMetrics *metrics.Metrics) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /readyz", monitor.ReadyHandler())
+	mux.HandleFunc("GET /livez", monitor.LiveHandler())
+	mux.Handle("GET /metrics", serviceMetrics.Handler())
+
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Ops.Port),
+		Handler:           mux,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+	}
 }
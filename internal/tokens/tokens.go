@@ -0,0 +1,26 @@
+package tokens
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+const tokenBytes = 32
+
+// Generate returns a random URL-safe token for the user and the SHA-256 hash that is persisted instead of it.
+func Generate() (token, hash string, err error) {
+	raw := make([]byte, tokenBytes)
+	if _, err = rand.Read(raw); err != nil {
+		return "", "", err
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(raw)
+	return token, Hash(token), nil
+}
+
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,47 @@
+package tokens
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	seen := make(map[string]bool)
+
+	for range 100 {
+		token, hash, err := Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		raw, err := base64.RawURLEncoding.DecodeString(token)
+		if err != nil || len(raw) != tokenBytes {
+			t.Fatalf("token %q is not %d URL-safe base64 bytes", token, tokenBytes)
+		}
+
+		if hash != Hash(token) {
+			t.Fatalf("hash of token %q does not match Hash", token)
+		}
+
+		if seen[token] {
+			t.Fatalf("token %q generated twice", token)
+		}
+		seen[token] = true
+	}
+}
+
+func TestHash(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{token: "", want: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{token: "abc", want: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+	}
+
+	for _, tt := range tests {
+		if got := Hash(tt.token); got != tt.want {
+			t.Errorf("Hash(%q) = %s, want %s", tt.token, got, tt.want)
+		}
+	}
+}
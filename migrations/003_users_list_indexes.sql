@@ -0,0 +1,13 @@
+-- Write your migrate up statements here
+CREATE INDEX idx_users_created_at_id ON users(created_at, id);
+CREATE INDEX idx_users_full_name_id ON users(full_name, id);
+CREATE INDEX idx_users_last_login_at_id ON users((COALESCE(last_login_at, 'epoch'::timestamp)), id);
+
+---- create above / drop below ----
+
+DROP INDEX idx_users_last_login_at_id;
+DROP INDEX idx_users_full_name_id;
+DROP INDEX idx_users_created_at_id;
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
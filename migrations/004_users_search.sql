@@ -0,0 +1,31 @@
+-- Write your migrate up statements here
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TYPE profile_visibility AS ENUM ('public', 'private');
+
+ALTER TABLE users ADD COLUMN profile_visibility profile_visibility NOT NULL DEFAULT 'public';
+
+ALTER TABLE users ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', full_name), 'A') ||
+    setweight(to_tsvector('simple', replace(slug, '-', ' ')), 'B')
+) STORED;
+
+CREATE INDEX idx_users_search_vector ON users USING GIN(search_vector);
+CREATE INDEX idx_users_full_name_trgm ON users USING GIN(full_name gin_trgm_ops);
+CREATE INDEX idx_users_slug_trgm ON users USING GIN(slug gin_trgm_ops);
+CREATE INDEX idx_users_email_lower_prefix ON users(lower(email) text_pattern_ops);
+
+---- create above / drop below ----
+
+DROP INDEX idx_users_email_lower_prefix;
+DROP INDEX idx_users_slug_trgm;
+DROP INDEX idx_users_full_name_trgm;
+DROP INDEX idx_users_search_vector;
+
+ALTER TABLE users DROP COLUMN search_vector;
+ALTER TABLE users DROP COLUMN profile_visibility;
+
+DROP TYPE IF EXISTS profile_visibility;
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
@@ -0,0 +1,19 @@
+-- Write your migrate up statements here
+CREATE TABLE users_password_reset_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    consumed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_users_password_reset_tokens_user_id ON users_password_reset_tokens(user_id, created_at);
+
+---- create above / drop below ----
+
+DROP INDEX idx_users_password_reset_tokens_user_id;
+DROP TABLE users_password_reset_tokens;
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
@@ -0,0 +1,38 @@
+-- Write your migrate up statements here
+CREATE TABLE users_mfa (
+    user_id INT PRIMARY KEY REFERENCES users(id),
+    secret_encrypted BYTEA NOT NULL,
+    last_used_step BIGINT,
+    confirmed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE users_mfa_recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_users_mfa_recovery_codes_user_id ON users_mfa_recovery_codes(user_id);
+
+CREATE TABLE users_mfa_challenges (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) NOT NULL,
+    challenge_hash CHAR(64) NOT NULL UNIQUE,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    consumed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+---- create above / drop below ----
+
+DROP TABLE users_mfa_challenges;
+DROP INDEX idx_users_mfa_recovery_codes_user_id;
+DROP TABLE users_mfa_recovery_codes;
+DROP TABLE users_mfa;
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
@@ -0,0 +1,22 @@
+-- Write your migrate up statements here
+CREATE TABLE users_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(128) NOT NULL,
+    user_id INT NOT NULL,
+    payload BYTEA NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    published_at TIMESTAMP
+);
+
+CREATE INDEX idx_users_outbox_pending ON users_outbox(next_attempt_at, id) WHERE published_at IS NULL;
+
+---- create above / drop below ----
+
+DROP INDEX idx_users_outbox_pending;
+DROP TABLE users_outbox;
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
@@ -0,0 +1,22 @@
+-- Write your migrate up statements here
+CREATE TABLE users_access_denials (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT,
+    role role NOT NULL,
+    method VARCHAR(255) NOT NULL,
+    reason TEXT NOT NULL,
+    client_ip VARCHAR(64),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_users_access_denials_created_at ON users_access_denials(created_at);
+CREATE INDEX idx_users_access_denials_user_id ON users_access_denials(user_id, created_at) WHERE user_id IS NOT NULL;
+
+---- create above / drop below ----
+
+DROP INDEX idx_users_access_denials_user_id;
+DROP INDEX idx_users_access_denials_created_at;
+DROP TABLE users_access_denials;
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
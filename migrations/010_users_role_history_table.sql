@@ -0,0 +1,20 @@
+-- Write your migrate up statements here
+CREATE TABLE users_role_history (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) NOT NULL,
+    old_role role NOT NULL,
+    new_role role NOT NULL,
+    changed_by INT REFERENCES users(id),
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_users_role_history_user_id ON users_role_history(user_id, created_at);
+
+---- create above / drop below ----
+
+DROP INDEX idx_users_role_history_user_id;
+DROP TABLE users_role_history;
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
@@ -0,0 +1,21 @@
+-- Write your migrate up statements here
+ALTER TABLE users
+    ADD COLUMN suspended_at TIMESTAMP,
+    ADD COLUMN suspended_until TIMESTAMP,
+    ADD COLUMN suspension_reason TEXT,
+    ADD COLUMN suspended_by INT REFERENCES users(id);
+
+CREATE INDEX idx_users_suspended_until ON users(suspended_until) WHERE suspended_at IS NOT NULL AND suspended_until IS NOT NULL;
+
+---- create above / drop below ----
+
+DROP INDEX idx_users_suspended_until;
+
+ALTER TABLE users
+    DROP COLUMN suspended_by,
+    DROP COLUMN suspension_reason,
+    DROP COLUMN suspended_until,
+    DROP COLUMN suspended_at;
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
@@ -0,0 +1,13 @@
+-- Write your migrate up statements here
+ALTER TABLE users ADD COLUMN purged_at TIMESTAMP;
+
+CREATE INDEX idx_users_deleted_at_pending_purge ON users(deleted_at) WHERE deleted_at IS NOT NULL AND purged_at IS NULL;
+
+---- create above / drop below ----
+
+DROP INDEX idx_users_deleted_at_pending_purge;
+
+ALTER TABLE users DROP COLUMN purged_at;
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
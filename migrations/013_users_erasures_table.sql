@@ -0,0 +1,17 @@
+-- Write your migrate up statements here
+CREATE TABLE users_erasures (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) NOT NULL,
+    requested_by INT REFERENCES users(id),
+    erased_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_users_erasures_user_id ON users_erasures(user_id);
+
+---- create above / drop below ----
+
+DROP INDEX idx_users_erasures_user_id;
+DROP TABLE users_erasures;
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
@@ -0,0 +1,12 @@
+-- Write your migrate up statements here
+ALTER TABLE users ALTER COLUMN pass_hash TYPE VARCHAR(255);
+ALTER TABLE users_password_history ALTER COLUMN pass_hash TYPE VARCHAR(255);
+
+---- create above / drop below ----
+
+-- Fails once Argon2id hashes, which are longer than 64 characters, have been stored.
+ALTER TABLE users_password_history ALTER COLUMN pass_hash TYPE VARCHAR(64);
+ALTER TABLE users ALTER COLUMN pass_hash TYPE VARCHAR(64);
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
@@ -0,0 +1,35 @@
+-- Write your migrate up statements here
+CREATE TABLE users_sessions (
+    id SERIAL PRIMARY KEY,
+    user_id INT REFERENCES users(id) NOT NULL,
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMP,
+    revoke_reason VARCHAR(32),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_users_sessions_user_id_active ON users_sessions(user_id) WHERE revoked_at IS NULL;
+
+CREATE TABLE users_refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    session_id INT REFERENCES users_sessions(id) NOT NULL,
+    user_id INT REFERENCES users(id) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    rotated_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_users_refresh_tokens_session_id ON users_refresh_tokens(session_id);
+
+---- create above / drop below ----
+
+DROP INDEX idx_users_refresh_tokens_session_id;
+DROP TABLE users_refresh_tokens;
+DROP INDEX idx_users_sessions_user_id_active;
+DROP TABLE users_sessions;
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
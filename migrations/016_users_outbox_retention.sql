@@ -0,0 +1,9 @@
+-- Write your migrate up statements here
+CREATE INDEX idx_users_outbox_published_at ON users_outbox(published_at) WHERE published_at IS NOT NULL;
+
+---- create above / drop below ----
+
+DROP INDEX idx_users_outbox_published_at;
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.